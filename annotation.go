@@ -0,0 +1,183 @@
+package entviz
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+
+	"entgo.io/ent/entc/gen"
+	"entgo.io/ent/schema"
+)
+
+// Annotation 是 entviz 的 schema 注解，用于调整实体在可视化中的展示方式。
+//
+// 使用方法：
+//
+//	func (Country) Annotations() []schema.Annotation {
+//		return []schema.Annotation{
+//			entviz.Shape("ellipse"),
+//		}
+//	}
+type Annotation struct {
+	// Shape 是实体节点在 vis-network 中的形状，为空时使用默认的 box。
+	Shape string `json:"shape,omitempty"`
+	// Deprecated 表示实体已废弃，页面中以灰色虚线框展示。
+	Deprecated bool `json:"deprecated,omitempty"`
+	// Links 是指向其他服务中实体的跨服务关系，在 MergeGraphs 合并多个服务的 schema 时使用。
+	Links []Link `json:"links,omitempty"`
+}
+
+// Link 是通过 ID 引用其他服务中实体的跨服务关系。
+type Link struct {
+	// Label 是关系的名称，通常是保存对方 ID 的字段，例如 user_id。
+	Label string `json:"label"`
+	// To 是目标实体在 MergeGraphs 结果中的名称，格式为 "<服务>.<实体>"，例如 users.User。
+	To string `json:"to"`
+}
+
+var _ interface {
+	schema.Annotation
+	schema.Merger
+} = (*Annotation)(nil)
+
+// Name 实现 schema.Annotation 接口。
+func (Annotation) Name() string {
+	return "EntViz"
+}
+
+// Merge 实现 schema.Merger 接口，使多个 entviz 注解可以同时作用于一个实体。
+func (a Annotation) Merge(other schema.Annotation) schema.Annotation {
+	var ant Annotation
+	switch other := other.(type) {
+	case Annotation:
+		ant = other
+	case *Annotation:
+		if other != nil {
+			ant = *other
+		}
+	default:
+		return a
+	}
+	if ant.Shape != "" {
+		a.Shape = ant.Shape
+	}
+	if ant.Deprecated {
+		a.Deprecated = true
+	}
+	a.Links = append(a.Links, ant.Links...)
+	return a
+}
+
+// Shape 返回设置实体节点形状的注解，例如用不同的形状区分字典表和核心实体。
+// 支持的形状有 box（默认）、ellipse、circle、database、diamond 和 text。
+func Shape(shape string) *Annotation {
+	return &Annotation{Shape: shape}
+}
+
+// Deprecated 返回将实体标记为已废弃的注解，用于在图中表明计划迁移或移除的实体。
+func Deprecated() *Annotation {
+	return &Annotation{Deprecated: true}
+}
+
+// CrossServiceLink 返回声明跨服务关系的注解：实体通过 label（通常是保存 ID 的字段）引用另一个服务中的实体 to，
+// to 的格式为 "<服务>.<实体>"。这类关系只在 MergeGraphs 生成的系统全景图中显示。
+func CrossServiceLink(label, to string) *Annotation {
+	return &Annotation{Links: []Link{{Label: label, To: to}}}
+}
+
+// nodeShapes 是可以在节点内部显示标签的 vis-network 形状。
+var nodeShapes = map[string]bool{
+	"box":      true,
+	"ellipse":  true,
+	"circle":   true,
+	"database": true,
+	"diamond":  true,
+	"text":     true,
+}
+
+// annotationOf 解析实体上的 entviz 注解。
+// 代码生成时注解以 JSON 解码后的形式保存，因此这里统一经过一次 JSON 转换。
+func annotationOf(n *gen.Type) Annotation {
+	var ant Annotation
+	raw, ok := n.Annotations[ant.Name()]
+	if !ok {
+		return ant
+	}
+	buf, err := json.Marshal(raw)
+	if err != nil {
+		return ant
+	}
+	_ = json.Unmarshal(buf, &ant)
+	return ant
+}
+
+// entityDescriptions 为实体节点设置 entityDescription 返回的说明，每个 schema 源文件只解析一次。
+// 需要在实体名称被翻译或替换为稳定 ID 之前调用。
+func entityDescriptions(graph Graph, g *gen.Graph) {
+	types := make(map[string]*gen.Type, len(g.Nodes))
+	for _, n := range g.Nodes {
+		types[n.Name] = n
+	}
+	docs := make(map[string]map[string]string)
+	for i, node := range graph.Nodes {
+		if n, ok := types[node.ID]; ok && node.Kind == "" {
+			graph.Nodes[i].Description = entityDescription(n, docs)
+		}
+	}
+}
+
+// entityDescription 返回实体的说明：优先使用 Ent 内置的 schema.Comment 注解，
+// 其次使用 schema 源文件中类型定义的文档注释。Ent 脚手架生成的默认注释
+// "User holds the schema definition for the User entity." 没有实际内容，会被忽略。
+// docs 缓存已解析的源文件中各类型的文档注释，以文件路径为键。
+func entityDescription(n *gen.Type, docs map[string]map[string]string) string {
+	var comment schema.CommentAnnotation
+	if raw, ok := n.Annotations[comment.Name()]; ok {
+		if buf, err := json.Marshal(raw); err == nil && json.Unmarshal(buf, &comment) == nil && comment.Text != "" {
+			return comment.Text
+		}
+	}
+	file := schemaFile(n)
+	if file == "" {
+		return ""
+	}
+	comments, ok := docs[file]
+	if !ok {
+		comments = docComments(file)
+		docs[file] = comments
+	}
+	doc := strings.TrimSpace(comments[n.Name])
+	if doc == n.Name+" holds the schema definition for the "+n.Name+" entity." {
+		return ""
+	}
+	return doc
+}
+
+// docComments 解析 schema 源文件，返回其中每个类型定义的文档注释，以类型名为键。
+// 文件无法解析时返回 nil。
+func docComments(file string) map[string]string {
+	f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.ParseComments)
+	if err != nil {
+		return nil
+	}
+	comments := make(map[string]string)
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			ts := spec.(*ast.TypeSpec)
+			// 单独定义的类型注释在 GenDecl 上，分组定义时在 TypeSpec 上。
+			switch {
+			case ts.Doc != nil:
+				comments[ts.Name.Name] = ts.Doc.Text()
+			case len(gd.Specs) == 1 && gd.Doc != nil:
+				comments[ts.Name.Name] = gd.Doc.Text()
+			}
+		}
+	}
+	return comments
+}
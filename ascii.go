@@ -0,0 +1,64 @@
+package entviz
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"text/tabwriter"
+
+	"entgo.io/ent/entc/gen"
+)
+
+// trailingSpace 匹配 tabwriter 在空白单元格后留下的行尾空格。
+var trailingSpace = regexp.MustCompile(`(?m)[ \t]+$`)
+
+// GenerateASCII 生成适合在终端中查看的纯文本 schema 摘要。
+// 每个实体列出其字段及类型，随后缩进列出从该实体出发的关系及其目标实体，
+// 便于在没有浏览器的情况下快速查看或使用 grep 搜索。
+//
+// 输出示例：
+//
+//	User
+//	  name  string
+//	  age   int     optional
+//	  -> pets: Pet (1:N)
+//
+// 参数：
+//   - g: 包含 schema 信息的 Ent 生成图
+//
+// 返回：
+//   - []byte: 文本内容
+//   - error: 如果生成过程中发生错误则返回错误
+func GenerateASCII(g *gen.Graph) ([]byte, error) {
+	graph := BuildGraph(g)
+	edges := make(map[string][]Edge)
+	for _, e := range graph.Edges {
+		edges[e.From] = append(edges[e.From], e)
+	}
+	var b bytes.Buffer
+	for i, n := range graph.Nodes {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(n.ID + "\n")
+		w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+		for _, f := range n.Fields {
+			flag := ""
+			if f.Optional {
+				flag = "optional"
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", f.Name, f.Type, flag)
+		}
+		if err := w.Flush(); err != nil {
+			return nil, err
+		}
+		for _, e := range edges[n.ID] {
+			fmt.Fprintf(&b, "  -> %s: %s", e.Label, e.To)
+			if e.Cardinality != "" {
+				fmt.Fprintf(&b, " (%s)", e.Cardinality)
+			}
+			b.WriteString("\n")
+		}
+	}
+	return trailingSpace.ReplaceAll(b.Bytes(), nil), nil
+}
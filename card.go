@@ -0,0 +1,65 @@
+package entviz
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"entgo.io/ent/entc/gen"
+)
+
+const (
+	// cardCharWidth 是卡片中等宽字体单个字符的近似宽度。
+	cardCharWidth = 8.4
+	// cardRowHeight 是卡片中每一行的高度。
+	cardRowHeight = 22
+	// cardPadding 是卡片内容与边框之间的水平间距。
+	cardPadding = 10
+)
+
+// GenerateEntityCard 将单个实体渲染为独立的 SVG 卡片，包含实体名称及其字段和类型，
+// 适合嵌入设计文档中展示特定的模型。
+//
+// 参数：
+//   - g: 包含 schema 信息的 Ent 生成图
+//   - name: 实体名称
+//
+// 返回：
+//   - []byte: SVG 图片内容
+//   - error: 如果图中不存在该实体则返回错误
+func GenerateEntityCard(g *gen.Graph, name string) ([]byte, error) {
+	var node *Node
+	graph := BuildGraph(g, WithTypeShortening(true))
+	for i := range graph.Nodes {
+		if graph.Nodes[i].ID == name {
+			node = &graph.Nodes[i]
+			break
+		}
+	}
+	if node == nil {
+		return nil, fmt.Errorf("entviz: entity %q not found", name)
+	}
+
+	// 名称列和类型列的宽度按最长的内容计算。
+	nameLen, typeLen := len(node.ID), 0
+	for _, f := range node.Fields {
+		nameLen = max(nameLen, len(f.Name))
+		typeLen = max(typeLen, len(f.Type))
+	}
+	typeX := cardPadding + float64(nameLen+2)*cardCharWidth
+	width := typeX + float64(typeLen)*cardCharWidth + cardPadding
+	height := cardRowHeight * (len(node.Fields) + 1)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%d" font-family="'Fira Code', monospace" font-size="14">`+"\n", width, height)
+	fmt.Fprintf(&b, `  <rect width="100%%" height="100%%" rx="4" fill="#1e1e1e"/>`+"\n")
+	fmt.Fprintf(&b, `  <rect width="100%%" height="%d" rx="4" fill="#3c3c3c"/>`+"\n", cardRowHeight)
+	fmt.Fprintf(&b, `  <text x="%d" y="%d" fill="white" font-weight="bold">%s</text>`+"\n", cardPadding, cardRowHeight-6, html.EscapeString(node.ID))
+	for i, f := range node.Fields {
+		y := cardRowHeight*(i+2) - 6
+		fmt.Fprintf(&b, `  <text x="%d" y="%d" fill="white">%s</text>`+"\n", cardPadding, y, html.EscapeString(f.Name))
+		fmt.Fprintf(&b, `  <text x="%.0f" y="%d" fill="#4EC9B0">%s</text>`+"\n", typeX, y, html.EscapeString(f.Type))
+	}
+	b.WriteString("</svg>\n")
+	return []byte(b.String()), nil
+}
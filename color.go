@@ -0,0 +1,41 @@
+package entviz
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+)
+
+// nodeColor 根据实体名称生成固定的浅色，格式为 #rrggbb。
+// 同一实体在每次生成和不同页面中始终使用相同的颜色，避免提交的页面因颜色变化产生无意义的差异。
+func nodeColor(name string) string {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	hue := float64(h.Sum32() % 360)
+	r, g, b := hslToRGB(hue, 0.65, 0.8)
+	return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+}
+
+// hslToRGB 将 HSL 颜色转换为 RGB，h 的单位为度，s 和 l 的取值范围为 [0, 1]。
+func hslToRGB(h, s, l float64) (r, g, b uint8) {
+	c := (1 - math.Abs(2*l-1)) * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - c/2
+	var r1, g1, b1 float64
+	switch {
+	case h < 60:
+		r1, g1 = c, x
+	case h < 120:
+		r1, g1 = x, c
+	case h < 180:
+		g1, b1 = c, x
+	case h < 240:
+		g1, b1 = x, c
+	case h < 300:
+		r1, b1 = x, c
+	default:
+		r1, b1 = c, x
+	}
+	channel := func(v float64) uint8 { return uint8(math.Round((v + m) * 255)) }
+	return channel(r1), channel(g1), channel(b1)
+}
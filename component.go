@@ -0,0 +1,107 @@
+package entviz
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"entgo.io/ent/entc/gen"
+)
+
+// groupComponents 计算图的连通分量，为每个节点设置所属分量的序号，
+// 并按分量稳定排序节点，使同一分量的实体在页面中相邻排列。
+// 分量按其第一个节点在原顺序中的位置编号，分量内部保持原有顺序。
+func groupComponents(graph Graph) Graph {
+	parent := make(map[string]string, len(graph.Nodes))
+	var find func(string) string
+	find = func(id string) string {
+		if parent[id] != id {
+			parent[id] = find(parent[id])
+		}
+		return parent[id]
+	}
+	for _, n := range graph.Nodes {
+		parent[n.ID] = n.ID
+	}
+	for _, e := range graph.Edges {
+		if _, ok := parent[e.From]; !ok {
+			continue
+		}
+		if _, ok := parent[e.To]; !ok {
+			continue
+		}
+		parent[find(e.From)] = find(e.To)
+	}
+	index := make(map[string]int)
+	for i, n := range graph.Nodes {
+		root := find(n.ID)
+		if _, ok := index[root]; !ok {
+			index[root] = len(index)
+		}
+		graph.Nodes[i].Component = index[root]
+	}
+	sort.SliceStable(graph.Nodes, func(i, j int) bool {
+		return graph.Nodes[i].Component < graph.Nodes[j].Component
+	})
+	return graph
+}
+
+// GenerateComponentPages 为 schema 的每个连通分量生成一个单独的可视化页面，写入 outDir 下的 <代表实体>.html。
+// 由多个相互独立的子系统组成的 schema 可以按子系统分别生成文档，比一个巨大的页面更清晰。
+// 代表实体是分量中关系最多的实体，数量相同时取名称靠前的实体。
+// outDir 不存在时会被创建，已有的同名文件会被覆盖。
+//
+// 参数：
+//   - g: 包含 schema 信息的 Ent 生成图
+//   - outDir: 输出目录
+//
+// 返回：
+//   - error: 如果生成页面、创建目录或写入文件时发生错误则返回错误
+func GenerateComponentPages(g *gen.Graph, outDir string) error {
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return err
+	}
+	o := newOptions()
+	for _, graph := range splitComponents(groupComponents(buildGraph(g, o))) {
+		page, err := renderPage(graph, o, packageData(g))
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(outDir, representative(graph)+".html"), page, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitComponents 将已由 groupComponents 分组的图拆分为每个连通分量一个子图，
+// 连通分量内的节点和边保持原有顺序。
+func splitComponents(graph Graph) []Graph {
+	var graphs []Graph
+	index := make(map[string]int, len(graph.Nodes))
+	for _, n := range graph.Nodes {
+		for len(graphs) <= n.Component {
+			graphs = append(graphs, Graph{})
+		}
+		graphs[n.Component].Nodes = append(graphs[n.Component].Nodes, n)
+		index[n.ID] = n.Component
+	}
+	for _, e := range graph.Edges {
+		if i, ok := index[e.From]; ok {
+			graphs[i].Edges = append(graphs[i].Edges, e)
+		}
+	}
+	return graphs
+}
+
+// representative 返回图中关系最多的实体，数量相同时取名称靠前的实体。
+func representative(graph Graph) string {
+	var best Node
+	for _, n := range graph.Nodes {
+		degree, bestDegree := n.InDegree+n.OutDegree, best.InDegree+best.OutDegree
+		if best.ID == "" || degree > bestDegree || degree == bestDegree && n.ID < best.ID {
+			best = n
+		}
+	}
+	return best.ID
+}
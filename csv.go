@@ -0,0 +1,66 @@
+package entviz
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+
+	"entgo.io/ent/entc/gen"
+)
+
+// GenerateRelationshipsCSV 生成包含所有关系的 CSV 表格，便于在电子表格中分析 schema 的关系结构。
+// 表头为 from,to,label,cardinality,required，每条关系占一行。
+//
+// 参数：
+//   - g: 包含 schema 信息的 Ent 生成图
+//
+// 返回：
+//   - []byte: CSV 内容
+//   - error: 如果写入 CSV 时发生错误则返回错误
+func GenerateRelationshipsCSV(g *gen.Graph) ([]byte, error) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	if err := w.Write([]string{"from", "to", "label", "cardinality", "required"}); err != nil {
+		return nil, err
+	}
+	for _, e := range BuildGraph(g).Edges {
+		if err := w.Write([]string{e.From, e.To, e.Label, e.Cardinality, strconv.FormatBool(e.Required)}); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// GenerateNotionCSV 生成每个字段占一行的 CSV 表格，可以直接导入为 Notion 数据库，
+// 便于非工程人员浏览 schema。表头为 Entity,Field,Type,Comment，
+// Notion 会将第一列作为标题，因此同一实体的字段在导入后可以按 Entity 分组。
+//
+// 参数：
+//   - g: 包含 schema 信息的 Ent 生成图
+//
+// 返回：
+//   - []byte: CSV 内容
+//   - error: 如果写入 CSV 时发生错误则返回错误
+func GenerateNotionCSV(g *gen.Graph) ([]byte, error) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	if err := w.Write([]string{"Entity", "Field", "Type", "Comment"}); err != nil {
+		return nil, err
+	}
+	for _, n := range BuildGraph(g, WithTypeShortening(true)).Nodes {
+		for _, f := range n.Fields {
+			if err := w.Write([]string{n.ID, f.Name, f.Type, f.Comment}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,81 @@
+package entviz
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"entgo.io/ent/entc/gen"
+)
+
+type (
+	// cytoscapeGraph 是 Cytoscape.js 的 elements 格式，可直接传给 cy.add 或 cytoscape({elements})。
+	cytoscapeGraph struct {
+		Elements cytoscapeElements `json:"elements"`
+	}
+
+	cytoscapeElements struct {
+		Nodes []cytoscapeElement[cytoscapeNode] `json:"nodes"`
+		Edges []cytoscapeElement[cytoscapeEdge] `json:"edges"`
+	}
+
+	// cytoscapeElement 包装 Cytoscape.js 元素的 data 字段。
+	cytoscapeElement[T any] struct {
+		Data T `json:"data"`
+	}
+
+	cytoscapeNode struct {
+		ID     string  `json:"id"`
+		Label  string  `json:"label"`
+		Kind   string  `json:"kind,omitempty"`
+		Fields []Field `json:"fields"`
+	}
+
+	cytoscapeEdge struct {
+		ID          string `json:"id"`
+		Source      string `json:"source"`
+		Target      string `json:"target"`
+		Label       string `json:"label"`
+		Cardinality string `json:"cardinality,omitempty"`
+		Required    bool   `json:"required"`
+		Kind        string `json:"kind,omitempty"`
+	}
+)
+
+// GenerateCytoscapeJSON 生成 Cytoscape.js 的 elements JSON，节点的 data 中带有实体的字段列表，
+// 可以直接接入基于 Cytoscape.js 的仪表盘并使用其布局算法。
+//
+// 参数：
+//   - g: 包含 schema 信息的 Ent 生成图
+//
+// 返回：
+//   - []byte: JSON 数据
+//   - error: 如果序列化过程中发生错误则返回错误
+func GenerateCytoscapeJSON(g *gen.Graph) ([]byte, error) {
+	graph := BuildGraph(g)
+	out := cytoscapeGraph{
+		Elements: cytoscapeElements{
+			Nodes: make([]cytoscapeElement[cytoscapeNode], 0, len(graph.Nodes)),
+			Edges: make([]cytoscapeElement[cytoscapeEdge], 0, len(graph.Edges)),
+		},
+	}
+	for _, n := range graph.Nodes {
+		out.Elements.Nodes = append(out.Elements.Nodes, cytoscapeElement[cytoscapeNode]{
+			Data: cytoscapeNode{ID: n.ID, Label: n.ID, Kind: n.Kind, Fields: n.Fields},
+		})
+	}
+	for i, e := range graph.Edges {
+		// Cytoscape.js 要求边也有唯一 id，同一对实体之间可能有多条关系，因此附加序号。
+		out.Elements.Edges = append(out.Elements.Edges, cytoscapeElement[cytoscapeEdge]{
+			Data: cytoscapeEdge{
+				ID:          fmt.Sprintf("%s.%s#%d", e.From, e.Label, i),
+				Source:      e.From,
+				Target:      e.To,
+				Label:       e.Label,
+				Cardinality: e.Cardinality,
+				Required:    e.Required,
+				Kind:        e.Kind,
+			},
+		})
+	}
+	return json.Marshal(out)
+}
@@ -0,0 +1,130 @@
+package entviz
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+
+	"entgo.io/ent/entc/gen"
+)
+
+type (
+	// dbmlTable 表示 DBML 输出中的一张表。
+	dbmlTable struct {
+		name    string
+		columns []dbmlColumn
+	}
+
+	// dbmlColumn 表示 DBML 表中的一列。
+	dbmlColumn struct {
+		name     string
+		typ      string
+		pk       bool
+		nullable bool
+	}
+)
+
+// dbmlPlainType 匹配无需加引号即可在 DBML 中使用的类型名称。
+var dbmlPlainType = regexp.MustCompile(`^\w+$`)
+
+// GenerateDBML 生成 DBML（dbdiagram.io 使用的格式）描述的 schema。
+// 表名和列名使用 Ent 迁移时的存储名称；外键列和多对多关联表
+// 与 Ent 的推导规则一致，外键列根据关系是否必填标记为 [null] 或 [not null]。
+//
+// 参数：
+//   - g: 包含 schema 信息的 Ent 生成图
+//
+// 返回：
+//   - []byte: DBML 文本
+//   - error: 如果生成过程中发生错误则返回错误
+func GenerateDBML(g *gen.Graph) ([]byte, error) {
+	var (
+		tables []*dbmlTable
+		byName = make(map[string]*dbmlTable)
+		refs   []string
+	)
+	addTable := func(name string) *dbmlTable {
+		if t, ok := byName[name]; ok {
+			return t
+		}
+		t := &dbmlTable{name: name}
+		byName[name] = t
+		tables = append(tables, t)
+		return t
+	}
+	addColumn := func(t *dbmlTable, c dbmlColumn) {
+		for _, existing := range t.columns {
+			if existing.name == c.name {
+				return
+			}
+		}
+		t.columns = append(t.columns, c)
+	}
+	for _, n := range g.Nodes {
+		t := addTable(n.Table())
+		if n.ID != nil {
+			addColumn(t, dbmlColumn{name: n.ID.StorageKey(), typ: n.ID.Type.String(), pk: true})
+		}
+		for _, f := range n.Fields {
+			addColumn(t, dbmlColumn{name: f.StorageKey(), typ: f.Type.String(), nullable: f.Optional || f.Nillable})
+		}
+	}
+	for _, n := range g.Nodes {
+		for _, e := range n.Edges {
+			if e.IsInverse() || n.ID == nil || e.Type.ID == nil {
+				continue
+			}
+			switch e.Rel.Type {
+			case gen.O2O, gen.O2M:
+				// 外键位于关系的目标表，引用当前实体的主键。
+				// 与 Ent 一致：非自引用且反向边必填时，外键列不可为空。
+				column := dbmlColumn{name: e.Rel.Column(), typ: n.ID.Type.String(), nullable: n == e.Type || e.Ref == nil || e.Ref.Optional}
+				addColumn(addTable(e.Rel.Table), column)
+				op := "<"
+				if e.Rel.Type == gen.O2O {
+					op = "-"
+				}
+				refs = append(refs, fmt.Sprintf("Ref: %s.%s %s %s.%s // %s", n.Table(), n.ID.StorageKey(), op, e.Rel.Table, column.name, e.Name))
+			case gen.M2O:
+				// 外键位于当前实体的表，非自引用且该边必填时不可为空。
+				column := dbmlColumn{name: e.Rel.Column(), typ: e.Type.ID.Type.String(), nullable: n == e.Type || e.Optional}
+				addColumn(addTable(e.Rel.Table), column)
+				refs = append(refs, fmt.Sprintf("Ref: %s.%s > %s.%s // %s", e.Rel.Table, column.name, e.Type.Table(), e.Type.ID.StorageKey(), e.Name))
+			case gen.M2M:
+				if e.Through != nil || len(e.Rel.Columns) != 2 {
+					continue
+				}
+				join := addTable(e.Rel.Table)
+				addColumn(join, dbmlColumn{name: e.Rel.Columns[0], typ: n.ID.Type.String()})
+				addColumn(join, dbmlColumn{name: e.Rel.Columns[1], typ: e.Type.ID.Type.String()})
+				refs = append(refs,
+					fmt.Sprintf("Ref: %s.%s < %s.%s // %s", n.Table(), n.ID.StorageKey(), e.Rel.Table, e.Rel.Columns[0], e.Name),
+					fmt.Sprintf("Ref: %s.%s < %s.%s // %s", e.Type.Table(), e.Type.ID.StorageKey(), e.Rel.Table, e.Rel.Columns[1], e.Name),
+				)
+			}
+		}
+	}
+	var b bytes.Buffer
+	for _, t := range tables {
+		fmt.Fprintf(&b, "Table %s {\n", t.name)
+		for _, c := range t.columns {
+			typ := c.typ
+			if !dbmlPlainType.MatchString(typ) {
+				typ = fmt.Sprintf("%q", typ)
+			}
+			switch {
+			case c.pk:
+				fmt.Fprintf(&b, "  %s %s [pk]\n", c.name, typ)
+			case c.nullable:
+				fmt.Fprintf(&b, "  %s %s [null]\n", c.name, typ)
+			default:
+				fmt.Fprintf(&b, "  %s %s [not null]\n", c.name, typ)
+			}
+		}
+		b.WriteString("}\n\n")
+	}
+	for _, r := range refs {
+		b.WriteString(r + "\n")
+	}
+	return b.Bytes(), nil
+}
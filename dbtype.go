@@ -0,0 +1,181 @@
+package entviz
+
+import (
+	"fmt"
+	"strings"
+
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/dialect/sql/schema"
+	"entgo.io/ent/entc/gen"
+	"entgo.io/ent/schema/field"
+)
+
+// dbTypes 保存各方言下字段类型对应的列类型，键 "" 为不区分方言的通用映射。
+// 字符串类型不在表中，由 stringDBType 根据长度单独处理。
+var dbTypes = map[string]map[field.Type]string{
+	"": {
+		field.TypeBool:    "boolean",
+		field.TypeTime:    "timestamp",
+		field.TypeJSON:    "json",
+		field.TypeUUID:    "uuid",
+		field.TypeBytes:   "blob",
+		field.TypeEnum:    "enum",
+		field.TypeInt8:    "smallint",
+		field.TypeInt16:   "smallint",
+		field.TypeInt32:   "integer",
+		field.TypeInt:     "bigint",
+		field.TypeInt64:   "bigint",
+		field.TypeUint8:   "smallint",
+		field.TypeUint16:  "smallint",
+		field.TypeUint32:  "integer",
+		field.TypeUint:    "bigint",
+		field.TypeUint64:  "bigint",
+		field.TypeFloat32: "float",
+		field.TypeFloat64: "double",
+	},
+	dialect.MySQL: {
+		field.TypeBool:    "bool",
+		field.TypeTime:    "timestamp",
+		field.TypeJSON:    "json",
+		field.TypeUUID:    "char(36)",
+		field.TypeBytes:   "blob",
+		field.TypeInt8:    "tinyint",
+		field.TypeInt16:   "smallint",
+		field.TypeInt32:   "int",
+		field.TypeInt:     "bigint",
+		field.TypeInt64:   "bigint",
+		field.TypeUint8:   "tinyint unsigned",
+		field.TypeUint16:  "smallint unsigned",
+		field.TypeUint32:  "int unsigned",
+		field.TypeUint:    "bigint unsigned",
+		field.TypeUint64:  "bigint unsigned",
+		field.TypeFloat32: "float",
+		field.TypeFloat64: "double",
+	},
+	dialect.Postgres: {
+		field.TypeBool:    "boolean",
+		field.TypeTime:    "timestamp with time zone",
+		field.TypeJSON:    "jsonb",
+		field.TypeUUID:    "uuid",
+		field.TypeBytes:   "bytea",
+		field.TypeEnum:    "character varying",
+		field.TypeInt8:    "smallint",
+		field.TypeInt16:   "smallint",
+		field.TypeInt32:   "integer",
+		field.TypeInt:     "bigint",
+		field.TypeInt64:   "bigint",
+		field.TypeUint8:   "smallint",
+		field.TypeUint16:  "integer",
+		field.TypeUint32:  "bigint",
+		field.TypeUint:    "bigint",
+		field.TypeUint64:  "bigint",
+		field.TypeFloat32: "real",
+		field.TypeFloat64: "double precision",
+	},
+	dialect.SQLite: {
+		field.TypeBool:    "bool",
+		field.TypeTime:    "datetime",
+		field.TypeJSON:    "json",
+		field.TypeUUID:    "uuid",
+		field.TypeBytes:   "blob",
+		field.TypeEnum:    "text",
+		field.TypeInt8:    "integer",
+		field.TypeInt16:   "integer",
+		field.TypeInt32:   "integer",
+		field.TypeInt:     "integer",
+		field.TypeInt64:   "integer",
+		field.TypeUint8:   "integer",
+		field.TypeUint16:  "integer",
+		field.TypeUint32:  "integer",
+		field.TypeUint:    "integer",
+		field.TypeUint64:  "integer",
+		field.TypeFloat32: "real",
+		field.TypeFloat64: "real",
+	},
+}
+
+// dbType 返回字段在指定方言下的列类型。
+// 通过 SchemaType 为该方言显式指定的类型优先；未知方言按通用映射处理。
+func dbType(f *gen.Field, name string) string {
+	c := f.Column()
+	if t, ok := c.SchemaType[name]; ok {
+		return t
+	}
+	types, ok := dbTypes[name]
+	if !ok {
+		name, types = "", dbTypes[""]
+	}
+	switch {
+	case c.Type == field.TypeString:
+		return stringDBType(c, name)
+	case c.Type == field.TypeEnum && name == dialect.MySQL:
+		values := make([]string, len(c.Enums))
+		for i, v := range c.Enums {
+			values[i] = "'" + strings.ReplaceAll(v, "'", "''") + "'"
+		}
+		return "enum(" + strings.Join(values, ", ") + ")"
+	case types[c.Type] != "":
+		return types[c.Type]
+	default:
+		// TypeOther 等没有通用映射的类型只能依赖 SchemaType。
+		return ""
+	}
+}
+
+// stringDBType 返回字符串字段的列类型，未指定长度时使用 Ent 迁移的默认长度。
+func stringDBType(c *schema.Column, name string) string {
+	size := c.Size
+	if size == 0 {
+		size = schema.DefaultStringLen
+	}
+	switch name {
+	case dialect.SQLite:
+		return "text"
+	case dialect.MySQL:
+		if size > 1<<16-1 {
+			return "longtext"
+		}
+		return fmt.Sprintf("varchar(%d)", size)
+	case dialect.Postgres:
+		if c.Size == 0 {
+			return "character varying"
+		}
+		return fmt.Sprintf("character varying(%d)", size)
+	default:
+		return fmt.Sprintf("varchar(%d)", size)
+	}
+}
+
+// sqlDefinition 返回字段在指定方言下类似 DDL 的列定义，例如 varchar(255) NOT NULL DEFAULT 'active' UNIQUE，
+// 其中包含列类型（及其长度）、非空约束、默认值和唯一约束。默认值只包含迁移会写入数据库的部分，
+// 由 Go 函数（例如 time.Now）生成的默认值不会出现在列定义中。
+func sqlDefinition(f *gen.Field, name string) string {
+	c := f.Column()
+	parts := []string{dbType(f, name)}
+	if !c.Nullable {
+		parts = append(parts, "NOT NULL")
+	}
+	if v := sqlDefault(c.Default, name); v != "" {
+		parts = append(parts, "DEFAULT "+v)
+	}
+	if c.Unique {
+		parts = append(parts, "UNIQUE")
+	}
+	return strings.TrimSpace(strings.Join(parts, " "))
+}
+
+// sqlDefault 返回列默认值在指定方言下的 SQL 表示，没有默认值时返回空字符串。
+func sqlDefault(v any, name string) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case schema.Expr:
+		return string(v)
+	case map[string]schema.Expr:
+		return string(v[name])
+	default:
+		return fmt.Sprint(v)
+	}
+}
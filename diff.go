@@ -0,0 +1,126 @@
+package entviz
+
+import (
+	"entgo.io/ent/entc/gen"
+)
+
+// 差异状态，记录在 Node、Field 和 Edge 的 Diff 属性中。
+const (
+	diffAdded   = "added"
+	diffRemoved = "removed"
+	diffChanged = "changed"
+)
+
+// RenderDiffHTML 生成展示两个版本 schema 差异的可视化页面，适合在代码评审中查看 schema 变更。
+// 页面展示合并后的图：新增的实体、字段和关系显示为绿色，删除的显示为红色，
+// 发生变化的（字段类型、可选性，关系基数等）显示为琥珀色。
+//
+// 参数：
+//   - old: 变更前的 Ent 生成图
+//   - new: 变更后的 Ent 生成图
+//
+// 返回：
+//   - []byte: 生成的 HTML 页面字节数组
+//   - error: 如果生成过程中发生错误则返回错误
+func RenderDiffHTML(old, new *gen.Graph) ([]byte, error) {
+	return renderHTML(diffGraphs(BuildGraph(old), BuildGraph(new)), newOptions())
+}
+
+// diffGraphs 合并两个版本的图，并在节点、字段和边上标记差异状态。
+// 合并后的顺序以新版本为准，被删除的内容排在最后。
+func diffGraphs(old, new Graph) Graph {
+	var merged Graph
+	oldNodes := make(map[string]Node, len(old.Nodes))
+	for _, n := range old.Nodes {
+		oldNodes[n.ID] = n
+	}
+	newNodes := make(map[string]bool, len(new.Nodes))
+	for _, n := range new.Nodes {
+		newNodes[n.ID] = true
+		prev, ok := oldNodes[n.ID]
+		if !ok {
+			n.Diff = diffAdded
+		} else if n.Fields = diffFields(prev.Fields, n.Fields); fieldsChanged(n.Fields) {
+			n.Diff = diffChanged
+		}
+		merged.Nodes = append(merged.Nodes, n)
+	}
+	for _, n := range old.Nodes {
+		if !newNodes[n.ID] {
+			n.Diff = diffRemoved
+			merged.Nodes = append(merged.Nodes, n)
+		}
+	}
+
+	edgeKey := func(e Edge) string { return e.From + "\x00" + e.Label + "\x00" + e.To }
+	oldEdges := make(map[string]Edge, len(old.Edges))
+	for _, e := range old.Edges {
+		oldEdges[edgeKey(e)] = e
+	}
+	newEdges := make(map[string]bool, len(new.Edges))
+	for _, e := range new.Edges {
+		newEdges[edgeKey(e)] = true
+		prev, ok := oldEdges[edgeKey(e)]
+		switch {
+		case !ok:
+			e.Diff = diffAdded
+		case prev.Cardinality != e.Cardinality || prev.Required != e.Required:
+			e.Diff = diffChanged
+		}
+		merged.Edges = append(merged.Edges, e)
+	}
+	for _, e := range old.Edges {
+		if !newEdges[edgeKey(e)] {
+			e.Diff = diffRemoved
+			merged.Edges = append(merged.Edges, e)
+		}
+	}
+
+	for i := range merged.Nodes {
+		merged.Nodes[i].InDegree, merged.Nodes[i].OutDegree = 0, 0
+	}
+	countDegrees(merged)
+	rels := relations(merged.Edges)
+	for i := range merged.Nodes {
+		merged.Nodes[i].Relations = rels[merged.Nodes[i].ID]
+	}
+	return merged
+}
+
+// diffFields 合并同一实体两个版本的字段并标记差异状态。
+func diffFields(old, new []Field) []Field {
+	oldFields := make(map[string]Field, len(old))
+	for _, f := range old {
+		oldFields[f.Name] = f
+	}
+	newFields := make(map[string]bool, len(new))
+	merged := make([]Field, 0, len(new))
+	for _, f := range new {
+		newFields[f.Name] = true
+		prev, ok := oldFields[f.Name]
+		switch {
+		case !ok:
+			f.Diff = diffAdded
+		case prev.Type != f.Type || prev.Optional != f.Optional:
+			f.Diff = diffChanged
+		}
+		merged = append(merged, f)
+	}
+	for _, f := range old {
+		if !newFields[f.Name] {
+			f.Diff = diffRemoved
+			merged = append(merged, f)
+		}
+	}
+	return merged
+}
+
+// fieldsChanged 判断字段列表中是否有任何差异。
+func fieldsChanged(fields []Field) bool {
+	for _, f := range fields {
+		if f.Diff != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package entviz
+
+import (
+	"entgo.io/ent/entc/gen"
+)
+
+// minEmbedFields 是被嵌入的实体至少需要的字段数量，避免只有一两个常见字段
+// （例如 name）的实体被误判为被其他实体嵌入。
+const minEmbedFields = 2
+
+// embedEdges 找出通过 Go 结构体嵌入复用其他 schema 的实体，返回 "embeds" 边。
+// Ent 不记录 schema 之间的嵌入关系，被嵌入的 schema 的 Fields 方法会被提升，
+// 因此当一个实体自身的字段严格包含另一个实体的全部字段（名称、类型和注释都相同）时，
+// 认为前者嵌入了后者。来自 mixin 的字段不参与比较；字段完全相同时无法判断方向，不生成边。
+func embedEdges(g *gen.Graph) []Edge {
+	own := make(map[string]map[string]string, len(g.Nodes))
+	for _, n := range g.Nodes {
+		sigs := make(map[string]string)
+		for _, f := range n.Fields {
+			if f.Position != nil && f.Position.MixedIn {
+				continue
+			}
+			sigs[f.Name] = f.Type.String() + ":" + f.Comment()
+		}
+		own[n.Name] = sigs
+	}
+	var edges []Edge
+	for _, n := range g.Nodes {
+		for _, base := range g.Nodes {
+			outer, inner := own[n.Name], own[base.Name]
+			if n == base || len(inner) < minEmbedFields || len(outer) <= len(inner) || !containsFields(outer, inner) {
+				continue
+			}
+			edges = append(edges, Edge{From: n.Name, To: base.Name, Label: "embeds", Kind: "embed"})
+		}
+	}
+	return edges
+}
+
+// containsFields 判断 outer 是否包含 inner 中的每个字段。
+func containsFields(outer, inner map[string]string) bool {
+	for name, sig := range inner {
+		if outer[name] != sig {
+			return false
+		}
+	}
+	return true
+}
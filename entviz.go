@@ -18,103 +18,772 @@ package entviz
 
 import (
 	"bytes"
+	"cmp"
 	"embed"
 	_ "embed"
 	"encoding/json"
+	"fmt"
 	"html/template"
 	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
 
 	"entgo.io/ent/entc"
 	"entgo.io/ent/entc/gen"
+	"entgo.io/ent/schema/field"
 )
 
 type (
-	// jsGraph 表示用于可视化的 JSON 可序列化图结构。
-	// 包含所有节点（实体）和边（关系），将由 JavaScript 可视化库渲染。
-	jsGraph struct {
-		Nodes []jsNode `json:"nodes"`
-		Edges []jsEdge `json:"edges"`
+	// Graph 是 schema 图的公开数据模型，其 JSON 结构与页面中嵌入的图数据一致。
+	// 包含所有节点（实体）和边（关系），将由 JavaScript 可视化库渲染，
+	// 也是各种导出格式共用的数据来源。
+	Graph struct {
+		Nodes []Node `json:"nodes"`
+		Edges []Edge `json:"edges"`
 	}
 
-	// jsNode 表示 schema 图中的单个实体。
+	// Node 表示 schema 图中的单个实体。
 	// 每个节点对应一个 Ent 类型，包含其字段定义。
-	jsNode struct {
-		ID     string    `json:"id"`
-		Fields []jsField `json:"fields"`
+	Node struct {
+		ID     string   `json:"id"`
+		Fields []Field  `json:"fields"`
+		X      *float64 `json:"x,omitempty"`
+		Y      *float64 `json:"y,omitempty"`
+		Shape  string   `json:"shape,omitempty"`
+		// Label 是实体的显示名称，仅在 ID 不是实体名称时设置，参见 WithStableIDs。
+		Label string `json:"label,omitempty"`
+		// Level 是分层布局中的层级提示，仅在按拓扑顺序排列时设置。
+		Level *int `json:"level,omitempty"`
+		// Kind 区分特殊节点，例如共享混入字段的 "mixin" 节点；实体节点为空。
+		Kind string `json:"kind,omitempty"`
+		// InDegree 和 OutDegree 分别是指向该实体和从该实体出发的关系数量。
+		InDegree  int `json:"inDegree"`
+		OutDegree int `json:"outDegree"`
+		// Deprecated 表示实体通过 Deprecated 注解被标记为已废弃。
+		Deprecated bool `json:"deprecated,omitempty"`
+		// SoftDelete 表示实体包含 WithSoftDeleteField 指定的软删除字段，例如 deleted_at。
+		SoftDelete bool `json:"softDelete,omitempty"`
+		// RowCount 是通过 WithRowCounts 提供的表行数估计，显示在节点名称旁边。
+		RowCount int64 `json:"rowCount,omitempty"`
+		// HasPolicy 和 HasHooks 表示实体的 schema 声明了隐私策略或钩子（包括来自混入的），
+		// 即该实体在运行时有额外的行为。
+		HasPolicy bool `json:"hasPolicy,omitempty"`
+		HasHooks  bool `json:"hasHooks,omitempty"`
+		// Highlighted 表示实体名称匹配 WithHighlightPattern 指定的正则表达式，页面中以醒目的边框显示。
+		Highlighted bool `json:"highlighted,omitempty"`
+		// Color 是根据实体名称生成的节点颜色，同一实体始终使用相同的颜色。
+		Color string `json:"color,omitempty"`
+		// Component 是实体所在连通分量的序号，仅在开启 WithComponents 时设置。
+		Component int `json:"component,omitempty"`
+		// Diff 是 RenderDiffHTML 中实体的差异状态：added、removed 或 changed。
+		Diff string `json:"diff,omitempty"`
+		// Relations 是与该实体相关的关系摘要，例如 "pets → Pet (1:N)"，显示在提示框中。
+		Relations []string `json:"relations,omitempty"`
+		// Description 是实体的说明，来自 schema.Comment 注解或 schema 类型的文档注释，仅在开启 WithEntityDescriptions 时设置。
+		Description string `json:"description,omitempty"`
+		// Constraints 是实体在关系和字段组合上的唯一约束，例如 "unique(number, owner)"，仅在开启 WithEdgeConstraints 时设置。
+		Constraints []string `json:"constraints,omitempty"`
+		// Client 是该实体在生成的客户端中的入口，例如 client.User，仅在开启 WithClientHints 时设置。
+		Client string `json:"client,omitempty"`
 	}
 
-	// jsEdge 表示 schema 中两个实体之间的关系。
+	// Edge 表示 schema 中两个实体之间的关系。
 	// 边是有向的，并带有关系名称标签。
-	jsEdge struct {
-		From  string `json:"from"`
-		To    string `json:"to"`
-		Label string `json:"label"`
+	// Accessor 是 Ent 为该关系生成的查询方法名称，例如 QueryPets；
+	// Cardinality 是关系的基数（1:1、1:N、N:1 或 N:N）；
+	// Required 表示创建实体时是否必须设置该关系。
+	Edge struct {
+		From        string `json:"from"`
+		To          string `json:"to"`
+		Label       string `json:"label"`
+		Accessor    string `json:"accessor"`
+		Cardinality string `json:"cardinality"`
+		Required    bool   `json:"required"`
+		// Kind 区分特殊的边，例如连接实体与混入节点的 "includes"；普通关系为空。
+		Kind string `json:"kind,omitempty"`
+		// Bidirectional 表示该关系在另一端定义了反向边，或是自引用的双向边，
+		// 页面中以两端都有箭头的单条边展示。
+		Bidirectional bool `json:"bidirectional,omitempty"`
+		// Immutable 表示该关系只能在创建实体时设置，之后不能修改。
+		Immutable bool `json:"immutable,omitempty"`
+		// Tree 表示该关系是树形的自引用（例如 parent/children），
+		// 页面中将其展开为下一层的子节点，而不是绘制为自环。
+		Tree bool `json:"tree,omitempty"`
+		// OnDelete 是通过 entsql.OnDelete 注解显式指定的外键删除行为，例如 CASCADE 或 RESTRICT；未指定时为空。
+		OnDelete string `json:"onDelete,omitempty"`
+		// Constraint 是包含该关系的唯一约束，多个约束以分号分隔，仅在开启 WithEdgeConstraints 时设置。
+		// 使用字符串而不是切片，以保持 Edge 可以比较。
+		Constraint string `json:"constraint,omitempty"`
+		// Diff 是 RenderDiffHTML 中关系的差异状态。
+		Diff string `json:"diff,omitempty"`
 	}
 
-	// jsField 表示实体中的单个字段定义。
+	// Field 表示实体中的单个字段定义。
 	// 包含字段名称和类型，用于在可视化中显示。
-	jsField struct {
-		Name    string `json:"name"`
-		Type    string `json:"type"`
-		Comment string `json:"comment"`
+	Field struct {
+		Name     string `json:"name"`
+		Type     string `json:"type"`
+		Comment  string `json:"comment"`
+		Optional bool   `json:"optional,omitempty"`
+		// Category 是字段类型的分类，例如 string、number、time、enum，用于在页面中着色。
+		Category string `json:"category,omitempty"`
+		// DBType 是字段对应的数据库列类型，取决于 WithDialect 选择的方言。
+		DBType string `json:"dbType,omitempty"`
+		// SQL 是字段在 WithDialect 选择的方言下类似 DDL 的列定义，包含非空、默认值、长度和唯一约束，
+		// 仅在开启 WithSQLConstraints 时设置。
+		SQL string `json:"sql,omitempty"`
+		// UpdateDefault 表示字段在实体更新时会自动设置，例如 updated_at。
+		UpdateDefault bool `json:"updateDefault,omitempty"`
+		// Tags 是通过 WithFieldAnnotations 选择展示的字段注解和结构体标签。
+		Tags []string `json:"tags,omitempty"`
+		// Muted 表示字段名称在 WithMuteFields 中，例如 created_at，页面中以暗淡的样式显示。
+		Muted bool `json:"muted,omitempty"`
+		// References 是外键字段所引用的实体主键，例如 User.id；普通字段为空。
+		References string `json:"references,omitempty"`
+		// Diff 是 RenderDiffHTML 中字段的差异状态。
+		Diff string `json:"diff,omitempty"`
 	}
 )
 
-// toJsGraph 将 Ent 的内部图表示转换为 JSON 可序列化结构。
-// 它通过以下方式将 Ent 的 gen.Graph 转换为 jsGraph：
+// BuildGraph 将 Ent 的生成图转换为公开的 Graph 模型。
+// 页面和各种导出格式都基于该模型生成，调用方也可以直接使用它做进一步处理。
+//
+// 参数：
+//   - g: 包含 schema 信息的 Ent 生成图
+//   - opts: 可选的生成配置项
+//
+// 返回：
+//   - Graph: 转换后的图模型
+func BuildGraph(g *gen.Graph, opts ...Option) Graph {
+	return buildGraph(g, newOptions(opts...))
+}
+
+// buildGraph 将 Ent 的内部图表示转换为 JSON 可序列化结构。
+// 它通过以下方式将 Ent 的 gen.Graph 转换为 Graph：
 //   - 提取每个节点（实体）及其字段
 //   - 为关系创建边，跳过反向边以避免重复
 //   - 保留实体名称作为节点 ID，关系名称作为边标签
+//   - 为每条边记录 Ent 生成的查询方法名称、基数以及是否必填
+//   - 如果配置了保存的坐标，则写入节点的初始位置
+//   - 读取 entviz.Shape 注解设置节点形状
+//   - 如果开启了内联基数，则将基数附加到边标签上
+//   - 按需将多个实体共享的混入字段提取为单独的节点
+//   - 按需记录实体在关系和字段组合上的唯一约束
+//   - 按需将枚举类型提取为列出取值的节点
+//   - 按需将带有中间实体（Through）的多对多关系拆分为经过中间实体的两条边
+//   - 跳过被排除的边，并按需移除因此失去所有关系的实体
+//   - 按需移除没有任何边的实体
+//   - 按需将实体名称和边的标签替换为译名
+//   - 统计每个实体的入度和出度
+//   - 按需合并同一对实体之间标签相同的平行边
+//   - 按需将实体按拓扑顺序排列，并设置分层布局的层级
+//   - 如果开启了类型简化，则缩短字段类型名称
+//   - 按需标记名称匹配正则表达式的实体
+//   - 按需将实体的 ID 替换为由 schema 文件和类型名称组成的稳定键，名称保留为显示标签
+//   - 最后依次应用通过 WithGraphTransform 注册的转换
 //
 // 参数：
 //   - g: 包含 schema 信息的 Ent 生成图
+//   - o: 生成配置
 //
 // 返回：
-//   - jsGraph: 适合 JSON 序列化和可视化的简化图结构
-func toJsGraph(g *gen.Graph) jsGraph {
-	graph := jsGraph{}
+//   - Graph: 适合 JSON 序列化和可视化的简化图结构
+func buildGraph(g *gen.Graph, o *options) Graph {
+	graph := Graph{}
+	// excluded 记录因 WithExcludeEdges 被移除的边所连接的实体。
+	excluded := make(map[string]bool)
+	// mixedIn 记录被提取到共享混入节点中的字段。
+	var (
+		mixins  []*mixinGroup
+		mixedIn map[string]map[string]bool
+	)
+	if o.mixinNodes {
+		mixins, mixedIn = sharedMixins(g)
+	}
 	for _, n := range g.Nodes {
-		node := jsNode{ID: n.Name}
-		for _, f := range n.Fields {
-			node.Fields = append(node.Fields, jsField{
-				Name:    f.Name,
-				Type:    f.Type.String(),
-				Comment: f.Comment(),
-			})
+		graph.Nodes = append(graph.Nodes, newNode(n, o, mixedIn[n.Name]))
+		graph.Edges = append(graph.Edges, newEdges(n, o, excluded)...)
+		if o.indexNodes {
+			nodes, edges := indexNodes(n, o)
+			graph.Nodes = append(graph.Nodes, nodes...)
+			graph.Edges = append(graph.Edges, edges...)
+		}
+	}
+	if o.embedEdges {
+		graph.Edges = append(graph.Edges, embedEdges(g)...)
+	}
+	if o.edgeConstraints {
+		edgeConstraints(graph, g)
+	}
+	if o.entityDescriptions {
+		entityDescriptions(graph, g)
+	}
+	if o.enumNodes {
+		nodes, edges := enumNodes(g)
+		graph.Nodes = append(graph.Nodes, nodes...)
+		graph.Edges = append(graph.Edges, edges...)
+	}
+	for _, m := range mixins {
+		node := Node{ID: m.id, Kind: "mixin"}
+		for _, f := range m.fields {
+			node.Fields = append(node.Fields, newField(f, o))
 		}
 		graph.Nodes = append(graph.Nodes, node)
-		for _, e := range n.Edges {
-			if e.IsInverse() {
-				continue
+		for _, name := range m.entities {
+			graph.Edges = append(graph.Edges, Edge{From: name, To: m.id, Label: "includes", Kind: "includes"})
+		}
+	}
+	if o.dropOrphans {
+		graph = dropOrphans(graph, excluded)
+	}
+	if o.connectedOnly {
+		all := make(map[string]bool, len(graph.Nodes))
+		for _, n := range graph.Nodes {
+			all[n.ID] = true
+		}
+		graph = dropOrphans(graph, all)
+	}
+	if o.labelTranslations != nil {
+		translateLabels(graph, o.labelTranslations)
+	}
+	if o.inlineCardinality {
+		for i, e := range graph.Edges {
+			if e.Kind == "" && e.Cardinality != "" {
+				graph.Edges[i].Label += " (" + e.Cardinality + ")"
+			}
+		}
+	}
+	countDegrees(graph)
+	rels := relations(graph.Edges)
+	for i := range graph.Nodes {
+		graph.Nodes[i].Relations = rels[graph.Nodes[i].ID]
+	}
+	if o.edgeBundling {
+		graph.Edges = bundleEdges(graph.Edges)
+	}
+	if o.topological {
+		// 循环依赖由 WithStrict 的检查报告，这里只决定排列顺序。
+		graph, _ = sortTopologically(graph)
+	}
+	if o.components {
+		graph = groupComponents(graph)
+	}
+	if o.highlightPattern != "" {
+		highlight(graph, o.highlightPattern)
+	}
+	if o.stableIDs {
+		stableIDs(graph, g, o.labelTranslations)
+	}
+	for _, transform := range o.transforms {
+		graph = transform(graph)
+	}
+	return graph
+}
+
+// newNode 将 Ent 类型转换为 Node，mixedIn 中的字段已被提取到混入节点，不再重复显示。
+// 入度和出度需要在所有边生成后再统计。
+func newNode(n *gen.Type, o *options, mixedIn map[string]bool) Node {
+	node := Node{ID: n.Name, Color: nodeColor(n.Name)}
+	pos, ok := o.savedPositions[n.Name]
+	if o.stableIDs {
+		// 页面导出的坐标以节点 ID 为键，开启稳定 ID 时优先使用稳定键对应的坐标。
+		if p, found := o.savedPositions[stableID(n)]; found {
+			pos, ok = p, true
+		}
+	}
+	if ok {
+		node.X, node.Y = &pos[0], &pos[1]
+	}
+	ant := annotationOf(n)
+	if nodeShapes[ant.Shape] {
+		node.Shape = ant.Shape
+	}
+	node.Deprecated = ant.Deprecated
+	node.RowCount = o.rowCounts[n.Name]
+	node.HasPolicy, node.HasHooks = n.NumPolicy() > 0, n.NumHooks() > 0
+	if o.clientHints {
+		node.Client = "client." + n.Name
+	}
+	for _, f := range n.Fields {
+		// 软删除字段通常来自混入，提取到混入节点之前检查。
+		if o.softDeleteField != "" && f.Name == o.softDeleteField {
+			node.SoftDelete = true
+		}
+		if mixedIn[f.Name] {
+			continue
+		}
+		node.Fields = append(node.Fields, newField(f, o))
+	}
+	return node
+}
+
+// newEdges 返回从实体 n 出发的关系。反向边会被跳过，因为它与正向边描述的是同一关系。
+// 因 WithExcludeEdges 被移除的边所连接的实体会记录到 excluded 中。
+func newEdges(n *gen.Type, o *options, excluded map[string]bool) []Edge {
+	var edges []Edge
+	for _, e := range n.Edges {
+		if e.IsInverse() {
+			continue
+		}
+		if o.excludeEdges[e.Name] || e.Ref != nil && o.excludeEdges[e.Ref.Name] {
+			excluded[n.Name], excluded[e.Type.Name] = true, true
+			continue
+		}
+		if o.throughNodes && e.M2M() && e.Through != nil {
+			// 通过显式的中间实体连接两端，而不是直接连接。
+			edges = append(edges,
+				Edge{From: n.Name, To: e.Through.Name, Label: e.Name, Cardinality: "1:N", Kind: "through"},
+				Edge{From: e.Through.Name, To: e.Type.Name, Label: e.Name, Cardinality: "N:1", Kind: "through"},
+			)
+			continue
+		}
+		edge := Edge{
+			From:        n.Name,
+			To:          e.Type.Name,
+			Label:       e.Name,
+			Accessor:    "Query" + pascal(e.Name),
+			Cardinality: cardinalities[e.Rel.Type],
+			Required:    !e.Optional,
+			// 反向边已被跳过，正向边的 Ref 指向其反向边。
+			Bidirectional: e.Ref != nil || e.Bidi,
+			// 不可变通常声明在反向边上，例如 edge.From("owner", User.Type).Unique().Immutable()。
+			Immutable: e.Immutable || e.Ref != nil && e.Ref.Immutable,
+			OnDelete:  onDelete(e),
+		}
+		if o.treeSelfRefs && e.Type == n && (e.Rel.Type == gen.O2M || e.Rel.Type == gen.M2O) {
+			edge.Tree = true
+		}
+		edges = append(edges, edge)
+	}
+	return edges
+}
+
+// newField 将 Ent 字段转换为 Field，并按配置处理字段类型。
+func newField(f *gen.Field, o *options) Field {
+	typ := f.Type.String()
+	if o.shortenTypes {
+		typ = shortenType(f.Type)
+	}
+	fld := Field{
+		Name:     f.Name,
+		Type:     typ,
+		Comment:  f.Comment(),
+		Optional: f.Optional,
+		Category: typeCategory(f.Type),
+		DBType:   dbType(f, o.dialect),
+		// UpdateDefault 与 Default 语义不同，单独标记。
+		UpdateDefault: f.UpdateDefault,
+		Tags:          fieldTags(f, o.fieldAnnotations),
+		References:    fieldReferences(f),
+		Muted:         slices.Contains(o.muteFields, f.Name),
+	}
+	if o.sqlConstraints {
+		fld.SQL = sqlDefinition(f, o.dialect)
+	}
+	return fld
+}
+
+// onDelete 返回关系上通过 entsql.OnDelete 注解指定的删除行为，注解可以写在关系的任意一侧。
+// 未指定时迁移会根据外键是否可空选择 SET NULL 或 NO ACTION，这里不作推断，返回空字符串。
+func onDelete(e *gen.Edge) string {
+	for _, side := range []*gen.Edge{e, e.Ref} {
+		if side == nil {
+			continue
+		}
+		if ant := side.EntSQL(); ant != nil && ant.OnDelete != "" {
+			return string(ant.OnDelete)
+		}
+	}
+	return ""
+}
+
+// fieldReferences 返回外键字段引用的实体主键，例如 owner_id 引用 User.id。
+// 只有通过边的 Field 方法声明的外键字段才能关联到对应的边。
+func fieldReferences(f *gen.Field) string {
+	if !f.IsEdgeField() {
+		return ""
+	}
+	e, err := f.Edge()
+	if err != nil || e.Type == nil {
+		return ""
+	}
+	id := "id"
+	if e.Type.ID != nil {
+		id = e.Type.ID.Name
+	}
+	return e.Type.Name + "." + id
+}
+
+// fieldTags 返回字段上被选中的注解和结构体标签。
+// 注解只显示名称；结构体标签显示为 key:"value" 的形式，与 Go 的写法一致。
+func fieldTags(f *gen.Field, names []string) []string {
+	var tags []string
+	for _, name := range names {
+		if _, ok := f.Annotations[name]; ok {
+			tags = append(tags, name)
+		}
+		if v, ok := reflect.StructTag(f.StructTag).Lookup(name); ok {
+			tags = append(tags, fmt.Sprintf("%s:%q", name, v))
+		}
+	}
+	return tags
+}
+
+// typeCategory 返回字段类型的分类。
+func typeCategory(t *field.TypeInfo) string {
+	switch {
+	case t == nil:
+		return ""
+	case t.Numeric():
+		return "number"
+	case t.Type == field.TypeString:
+		return "string"
+	case t.Type == field.TypeOther:
+		return "other"
+	default:
+		return shortTypeNames[t.Type]
+	}
+}
+
+// highlight 标记名称匹配正则表达式 pattern 的实体。正则表达式无效时不标记任何实体，
+// 生成页面时由 checkHighlightPattern 报告该错误。
+func highlight(graph Graph, pattern string) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return
+	}
+	for i, n := range graph.Nodes {
+		graph.Nodes[i].Highlighted = n.Kind == "" && re.MatchString(n.ID)
+	}
+}
+
+// checkHighlightPattern 检查 WithHighlightPattern 设置的正则表达式，无效时返回错误。
+func checkHighlightPattern(o *options) error {
+	if o.highlightPattern == "" {
+		return nil
+	}
+	if _, err := regexp.Compile(o.highlightPattern); err != nil {
+		return fmt.Errorf("entviz: invalid highlight pattern %q: %w", o.highlightPattern, err)
+	}
+	return nil
+}
+
+// translateLabels 将实体名称和边的标签替换为 translations 中的译名，没有译名的保持不变。
+// 边的两端随实体名称一起替换，使边仍然连接到对应的节点。
+func translateLabels(graph Graph, translations map[string]string) {
+	translate := func(s string) string {
+		if t, ok := translations[s]; ok {
+			return t
+		}
+		return s
+	}
+	for i := range graph.Nodes {
+		graph.Nodes[i].ID = translate(graph.Nodes[i].ID)
+	}
+	for i, e := range graph.Edges {
+		graph.Edges[i].From, graph.Edges[i].To, graph.Edges[i].Label = translate(e.From), translate(e.To), translate(e.Label)
+	}
+}
+
+// stableIDs 将实体节点的 ID 替换为 stableID 返回的稳定键，原来的名称（可能已被翻译）保存在 Label 中，
+// 边的两端随之更新。混入、索引等特殊节点不是 schema 中的类型，保持不变。
+func stableIDs(graph Graph, g *gen.Graph, translations map[string]string) {
+	ids := make(map[string]string, len(g.Nodes))
+	for _, n := range g.Nodes {
+		name := n.Name
+		if t, ok := translations[name]; ok {
+			name = t
+		}
+		ids[name] = stableID(n)
+	}
+	for i, n := range graph.Nodes {
+		if id, ok := ids[n.ID]; ok && id != n.ID && n.Kind == "" {
+			graph.Nodes[i].ID, graph.Nodes[i].Label = id, n.ID
+		}
+	}
+	for i, e := range graph.Edges {
+		if id, ok := ids[e.From]; ok {
+			graph.Edges[i].From = id
+		}
+		if id, ok := ids[e.To]; ok {
+			graph.Edges[i].To = id
+		}
+	}
+}
+
+// stableID 返回由定义类型的 schema 文件名和类型名称组成的键，例如 user.go#User。
+// 类型的位置中包含行号，编辑文件时会变化，因此只使用文件名；位置未知时（例如 schema 不是从源码加载的）使用类型名称。
+func stableID(n *gen.Type) string {
+	file := schemaFile(n)
+	if file == "" {
+		return n.Name
+	}
+	return filepath.Base(file) + "#" + n.Name
+}
+
+// schemaFile 返回定义实体的 schema 源文件路径，即去掉行号后的类型位置；位置未知时返回空字符串。
+func schemaFile(n *gen.Type) string {
+	pos := n.Pos()
+	if i := strings.LastIndexByte(pos, ':'); i > 0 {
+		pos = pos[:i]
+	}
+	return pos
+}
+
+// countDegrees 根据图中的边计算每个实体的入度和出度。
+func countDegrees(graph Graph) {
+	index := make(map[string]int, len(graph.Nodes))
+	for i, n := range graph.Nodes {
+		index[n.ID] = i
+	}
+	for _, e := range graph.Edges {
+		if i, ok := index[e.From]; ok {
+			graph.Nodes[i].OutDegree++
+		}
+		if i, ok := index[e.To]; ok {
+			graph.Nodes[i].InDegree++
+		}
+	}
+}
+
+// relations 按实体汇总与其相关的关系，从该实体的角度描述每条关系：
+// 出边显示为 "pets → Pet (1:N)"，入边显示为 "User.pets ← (N:1)"，基数按该实体的方向反转。
+// 混入、索引等特殊的边不是实体之间的关系，不参与汇总；跨服务关系（link）参与汇总。
+func relations(edges []Edge) map[string][]string {
+	reversed := map[string]string{"1:N": "N:1", "N:1": "1:N"}
+	card := func(c string) string {
+		if c == "" {
+			return ""
+		}
+		return " (" + c + ")"
+	}
+	rels := make(map[string][]string)
+	for _, e := range edges {
+		if e.Kind != "" && e.Kind != "through" && e.Kind != "link" {
+			continue
+		}
+		rels[e.From] = append(rels[e.From], e.Label+" → "+e.To+card(e.Cardinality))
+		if e.To != e.From {
+			in := e.Cardinality
+			if r, ok := reversed[in]; ok {
+				in = r
 			}
-			graph.Edges = append(graph.Edges, jsEdge{
-				From:  n.Name,
-				To:    e.Type.Name,
-				Label: e.Name,
-			})
+			rels[e.To] = append(rels[e.To], e.From+"."+e.Label+" ←"+card(in))
 		}
+	}
+	return rels
+}
 
+// dropOrphans 移除 candidates 中已经没有任何关系的实体。
+func dropOrphans(graph Graph, candidates map[string]bool) Graph {
+	connected := make(map[string]bool)
+	for _, e := range graph.Edges {
+		connected[e.From], connected[e.To] = true, true
+	}
+	nodes := graph.Nodes[:0]
+	for _, n := range graph.Nodes {
+		if candidates[n.ID] && !connected[n.ID] {
+			continue
+		}
+		nodes = append(nodes, n)
 	}
+	graph.Nodes = nodes
 	return graph
 }
 
+// bundleEdges 将同一对实体之间方向和标签都相同的多条边合并为一条，
+// 标签附带合并的数量，例如 "posts (×3)"。合并后的边保留第一条边的其余属性和位置。
+func bundleEdges(edges []Edge) []Edge {
+	type key struct{ from, to, label string }
+	counts := make(map[key]int)
+	for _, e := range edges {
+		counts[key{e.From, e.To, e.Label}]++
+	}
+	bundled := make([]Edge, 0, len(counts))
+	seen := make(map[key]bool)
+	for _, e := range edges {
+		k := key{e.From, e.To, e.Label}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		if n := counts[k]; n > 1 {
+			e.Label = fmt.Sprintf("%s (×%d)", e.Label, n)
+		}
+		bundled = append(bundled, e)
+	}
+	return bundled
+}
+
+var (
+	// cardinalities 将 Ent 的关系类型映射为边的基数。
+	cardinalities = map[gen.Rel]string{
+		gen.O2O: "1:1",
+		gen.O2M: "1:N",
+		gen.M2O: "N:1",
+		gen.M2M: "N:N",
+	}
+	// defaultArrowStyles 是各基数的边在目标端使用的 vis-network 箭头类型，
+	// 近似 ER 图中的乌鸦脚表示法：目标端为"多"时使用 crow，为"一"时使用 bar。
+	defaultArrowStyles = map[string]string{
+		"1:1": "bar",
+		"1:N": "crow",
+		"N:1": "bar",
+		"N:N": "crow",
+	}
+	// shortTypeNames 是常见字段类型的简短名称。
+	shortTypeNames = map[field.Type]string{
+		field.TypeUUID:  "uuid",
+		field.TypeTime:  "time",
+		field.TypeJSON:  "json",
+		field.TypeBytes: "bytes",
+		field.TypeEnum:  "enum",
+		field.TypeBool:  "bool",
+	}
+	// pascal 与 Ent 代码生成使用相同的规则将名称转换为 PascalCase。
+	pascal = gen.Funcs["pascal"].(func(string) string)
+	// pkgQualifier 匹配类型名称中的包名前缀，例如 uuid.UUID 中的 "uuid."。
+	pkgQualifier = regexp.MustCompile(`\w+\.`)
+)
+
+// shortenType 返回字段类型的简短名称。
+// 常见类型直接使用简短名称；通过 GoType 指定的自定义类型
+// 去掉包名，并在括号中附带其底层类型。
+func shortenType(t *field.TypeInfo) string {
+	kind, ok := shortTypeNames[t.Type]
+	if !ok {
+		kind = t.Type.String()
+	}
+	switch t.Type {
+	case field.TypeUUID, field.TypeTime, field.TypeJSON:
+		return kind
+	}
+	if t.Ident == "" {
+		return kind
+	}
+	name := pkgQualifier.ReplaceAllString(t.Ident, "")
+	if t.Type == field.TypeOther {
+		return name
+	}
+	return name + "(" + kind + ")"
+}
+
 var (
 	//go:embed viz.tmpl
 	tmplhtml string
 	//go:embed entviz.go.tmpl
 	tmplfile string
 	//go:embed assets
-	assets embed.FS
-	viztmpl  = template.Must(template.New("viz").Parse(tmplhtml))
+	assets  embed.FS
+	viztmpl = template.Must(template.New("viz").Parse(tmplhtml))
 )
 
 type templateData struct {
-	FiraCodeCSS    template.CSS
-	VisNetworkJS   template.JS
-	RandomColorJS  template.JS
-	GraphJSON      template.JS
+	FiraCodeCSS   template.CSS
+	VisNetworkJS  template.JS
+	RandomColorJS template.JS
+	GraphJSON     template.JS
+	// Pills 控制字段类型是否以彩色标签的形式显示。
+	Pills bool
+	// Warnings 是显示在页面顶部的警告信息。
+	Warnings []string
+	// Collapsed 控制节点是否默认折叠，只显示实体名称。
+	Collapsed bool
+	// MaxFields 是节点中最多显示的字段数量，0 表示不限制。
+	MaxFields int
+	// Deterministic 控制节点颜色和初始布局是否固定，而不是每次打开页面时随机生成。
+	Deterministic bool
+	// NodeMinWidth 和 NodeMaxWidth 是节点宽度的范围。
+	NodeMinWidth int
+	NodeMaxWidth int
+	// LevelSeparation 和 NodeSpacing 是分层布局中层与层、同层节点之间的距离。
+	LevelSeparation int
+	NodeSpacing     int
+	// Package 和 Module 是生成代码的包路径及其所在的模块，显示在页面顶部。
+	Package string
+	Module  string
+	// Components 控制是否拉开不同连通分量之间的距离。
+	Components bool
+	// ArrowStyles 是各基数的边使用的箭头类型。
+	ArrowStyles map[string]string
+	// CustomCSS 是用户提供的样式，放在默认样式之后以便覆盖。
+	CustomCSS template.CSS
+	// FieldGrouping 是字段的分组方式，"required-first" 表示必填字段在前，与可选字段之间以分隔线隔开。
+	FieldGrouping string
+	// EdgeFontSize、EdgeFontBackground 和 EdgeFontBold 是边标签的字号、背景色以及是否加粗。
+	EdgeFontSize       int
+	EdgeFontBackground string
+	EdgeFontBold       bool
+	// Title 和 Description 是显示在页面顶部的标题和说明，为空时不显示。
+	Title       string
+	Description string
+	// Direction 是分层布局的 vis-network 方向（LR、RL、UD 或 DU），为空时使用默认方向。
+	Direction string
+	// Nav 是分页输出（WithPageSize）中页面之间的导航信息。
+	Nav pageNav
+	// Summary 是页面底部统计表的各行，仅在开启 WithSummaryTable 时设置。
+	Summary []summaryRow
+	// NoVendor 控制是否只生成嵌入宿主页面的片段：不包含 html、head 和 body，也不内联 vis-network。
+	NoVendor bool
+	// CSSScope 是页面样式中每个选择器的前缀，生成片段时为 ".entviz "，使样式不影响宿主页面。
+	CSSScope template.CSS
+}
+
+// Asset 返回页面内联使用的静态资源内容，便于自行托管这些文件。
+// 可用的资源有 fira_code.css、vis-network.min.js 和 randomcolor.min.js。
+// 另外，graph.schema.json 是描述 Graph JSON 结构的 JSON Schema（draft-07），可用于校验手工编辑的图数据。
+//
+// 参数：
+//   - name: 资源文件名
+//
+// 返回：
+//   - []byte: 资源内容的副本，修改它不会影响生成的页面
+//   - error: 如果资源不存在则返回错误
+func Asset(name string) ([]byte, error) {
+	sub, err := fs.Sub(assets, "assets")
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadFile(sub, name)
+}
+
+// layoutDirections 是 WithDirection 支持的方向到 vis-network 分层布局方向的映射。
+var layoutDirections = map[string]string{
+	"LR": "LR",
+	"RL": "RL",
+	"TB": "UD",
+	"BT": "DU",
+}
+
+// summaryRow 是统计表中一个实体的字段数量、入边数量和出边数量。
+type summaryRow struct {
+	Entity          string
+	Fields, In, Out int
+}
+
+// summaryRows 返回图中每个实体的统计信息，混入、枚举等特殊节点不计入。
+func summaryRows(graph Graph) []summaryRow {
+	var rows []summaryRow
+	for _, n := range graph.Nodes {
+		if n.Kind != "" {
+			continue
+		}
+		rows = append(rows, summaryRow{Entity: cmp.Or(n.Label, n.ID), Fields: len(n.Fields), In: n.InDegree, Out: n.OutDegree})
+	}
+	return rows
+}
+
+// arrowStyles 返回默认箭头类型被 overrides 覆盖后的结果。
+func arrowStyles(overrides map[string]string) map[string]string {
+	styles := maps.Clone(defaultArrowStyles)
+	maps.Copy(styles, overrides)
+	return styles
 }
 
 // generateHTML 生成包含 schema 可视化的完整 HTML 页面。
@@ -125,35 +794,95 @@ type templateData struct {
 //
 // 参数：
 //   - g: 包含 schema 信息的 Ent 生成图
+//   - o: 生成配置
 //
 // 返回：
 //   - []byte: 生成的 HTML 页面字节数组
 //   - error: 如果生成过程中发生错误则返回错误
-func generateHTML(g *gen.Graph) ([]byte, error) {
-	firaCodeCSS, err := fs.ReadFile(assets, "assets/fira_code.css")
-	if err != nil {
+func generateHTML(g *gen.Graph, o *options) ([]byte, error) {
+	if err := checkHighlightPattern(o); err != nil {
 		return nil, err
 	}
-	visNetworkJS, err := fs.ReadFile(assets, "assets/vis-network.min.js")
+	return renderPage(buildGraph(g, o), o, packageData(g))
+}
+
+// renderHTML 将已转换的图序列化并渲染为完整的 HTML 页面，用于没有 gen.Config 的图。
+func renderHTML(graph Graph, o *options) ([]byte, error) {
+	return renderPage(graph, o, templateData{})
+}
+
+// packageData 返回页面顶部显示的生成代码的包路径和模块路径，由 g 的 gen.Config 得到。
+func packageData(g *gen.Graph) templateData {
+	if g.Config == nil {
+		return templateData{}
+	}
+	return templateData{Package: g.Config.Package, Module: modulePath(g.Config.Target)}
+}
+
+// renderPage 将已转换的图序列化并渲染为 HTML 页面。page 提供页面本身的信息：
+// 生成代码的包路径和模块路径，以及分页输出中页面之间的导航。
+// 页面所需的字体、vis-network 和 randomColor 资源都会内联到页面中，
+// 与页面展示相关的配置通过 templateData 传给模板。
+func renderPage(graph Graph, o *options, page templateData) ([]byte, error) {
+	firaCodeCSS, err := Asset("fira_code.css")
 	if err != nil {
 		return nil, err
 	}
-	randomColorJS, err := fs.ReadFile(assets, "assets/randomcolor.min.js")
+	var visNetworkJS []byte
+	if !o.noVendor {
+		if visNetworkJS, err = Asset("vis-network.min.js"); err != nil {
+			return nil, err
+		}
+	}
+	randomColorJS, err := Asset("randomcolor.min.js")
 	if err != nil {
 		return nil, err
 	}
 
-	graph := toJsGraph(g)
 	graphJSON, err := json.Marshal(&graph)
 	if err != nil {
 		return nil, err
 	}
+	if o.omitEmpty {
+		if graphJSON, err = omitEmptyJSON(graphJSON); err != nil {
+			return nil, err
+		}
+	}
 
 	data := templateData{
-		FiraCodeCSS:   template.CSS(firaCodeCSS),
-		VisNetworkJS:  template.JS(visNetworkJS),
-		RandomColorJS: template.JS(randomColorJS),
-		GraphJSON:     template.JS(graphJSON),
+		FiraCodeCSS:        template.CSS(firaCodeCSS),
+		VisNetworkJS:       template.JS(visNetworkJS),
+		RandomColorJS:      template.JS(randomColorJS),
+		GraphJSON:          template.JS(graphJSON),
+		Pills:              o.pills,
+		Warnings:           o.warnings,
+		Collapsed:          o.collapsed,
+		MaxFields:          o.maxFields,
+		Deterministic:      o.deterministic,
+		NodeMinWidth:       cmp.Or(o.nodeMinWidth, defaultNodeWidth),
+		NodeMaxWidth:       cmp.Or(o.nodeMaxWidth, defaultNodeWidth),
+		LevelSeparation:    cmp.Or(o.levelSeparation, defaultLevelSeparation),
+		NodeSpacing:        cmp.Or(o.nodeSpacing, defaultNodeSpacing),
+		Package:            page.Package,
+		Module:             page.Module,
+		Components:         o.components,
+		ArrowStyles:        arrowStyles(o.arrowStyles),
+		CustomCSS:          template.CSS(o.customCSS),
+		FieldGrouping:      o.fieldGrouping,
+		EdgeFontSize:       cmp.Or(o.edgeFontSize, defaultEdgeFontSize),
+		EdgeFontBackground: cmp.Or(o.edgeFontBackground, defaultEdgeFontBackground),
+		EdgeFontBold:       o.edgeFontBold,
+		Title:              o.title,
+		Description:        o.description,
+		NoVendor:           o.noVendor,
+		Direction:          layoutDirections[o.direction],
+		Nav:                page.Nav,
+	}
+	if o.summaryTable {
+		data.Summary = summaryRows(graph)
+	}
+	if o.noVendor {
+		data.CSSScope = ".entviz "
 	}
 
 	var b bytes.Buffer
@@ -163,11 +892,78 @@ func generateHTML(g *gen.Graph) ([]byte, error) {
 	return b.Bytes(), nil
 }
 
+// GenerateHTMLNoVendor 生成可以嵌入已有页面的可视化片段，包含图的容器、样式和初始化脚本，
+// 但不包含 html、head 和 body 标签，也不内联 vis-network 库，适合已经加载了 vis-network 的文档站点。
+// 宿主页面需要在片段之前以全局变量 window.vis 的形式加载 vis-network，片段中的所有样式都限定在 .entviz 容器内，不影响宿主页面。
+//
+// 参数：
+//   - g: 包含 schema 信息的 Ent 生成图
+//
+// 返回：
+//   - []byte: 生成的 HTML 片段
+//   - error: 如果生成过程中发生错误则返回错误
+func GenerateHTMLNoVendor(g *gen.Graph) ([]byte, error) {
+	o := newOptions()
+	o.noVendor = true
+	return generateHTML(g, o)
+}
+
+// RenderJSON 使用已序列化的图 JSON 生成可视化 HTML 页面，完全绕过 gen.Graph。
+// 适用于通过其他方式生成图 JSON，或缓存中间 JSON 的流水线。
+// JSON 的结构必须与页面中嵌入的图数据一致：
+//   {"nodes": [{"id": "User", "fields": [...]}], "edges": [{"from": "User", "to": "Pet", "label": "pets"}]}
+//
+// 参数：
+//   - graphJSON: 序列化后的图数据
+//
+// 返回：
+//   - []byte: 生成的 HTML 页面字节数组
+//   - error: 如果 JSON 结构不合法或生成过程中发生错误则返回错误
+func RenderJSON(graphJSON []byte) ([]byte, error) {
+	graph, err := decodeGraph(graphJSON)
+	if err != nil {
+		return nil, err
+	}
+	return renderHTML(graph, newOptions())
+}
+
+// decodeGraph 严格解析图 JSON，并校验节点和边之间的引用关系。
+func decodeGraph(data []byte) (Graph, error) {
+	// schema-viz.json 中的邻接表可以由图数据重新计算，读取时忽略。
+	var m manifest
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&m); err != nil {
+		return Graph{}, fmt.Errorf("entviz: invalid graph JSON: %w", err)
+	}
+	graph := m.Graph
+	ids := make(map[string]struct{}, len(graph.Nodes))
+	for i, n := range graph.Nodes {
+		if n.ID == "" {
+			return Graph{}, fmt.Errorf("entviz: invalid graph JSON: nodes[%d] has no id", i)
+		}
+		if _, ok := ids[n.ID]; ok {
+			return Graph{}, fmt.Errorf("entviz: invalid graph JSON: duplicate node %q", n.ID)
+		}
+		ids[n.ID] = struct{}{}
+	}
+	for i, e := range graph.Edges {
+		for _, end := range []string{e.From, e.To} {
+			if _, ok := ids[end]; !ok {
+				return Graph{}, fmt.Errorf("entviz: invalid graph JSON: edges[%d] references unknown node %q", i, end)
+			}
+		}
+	}
+	return graph, nil
+}
+
 // VisualizeSchema 是一个 Ent 钩子，用于生成可视化 schema 图的静态 HTML 页面。
 // 该钩子在 Ent 代码生成流程中运行：
-//   1. 首先调用下一个生成器完成标准代码生成
-//   2. 然后生成 schema 可视化 HTML
-//   3. 将 HTML 文件写入目标目录（默认为 ent/schema-viz.html）
+//   1. 开启严格模式（WithStrict）时，先检查完整的 schema，存在问题则直接失败，不生成任何代码
+//   2. 调用下一个生成器完成标准代码生成
+//   3. 然后生成 schema 可视化 HTML
+//   4. 将 HTML 文件写入目标目录（默认为 ent/schema-viz.html）
+//   5. 同时写入 JSON、DOT 和 Mermaid 格式，供生成的 ServeEntviz 按请求的格式返回
 //
 // 参数：
 //   - next: 下一个生成器，用于完成标准代码生成
@@ -175,17 +971,97 @@ func generateHTML(g *gen.Graph) ([]byte, error) {
 // 返回：
 //   - gen.Generator: 包装后的生成器，会在标准生成后添加可视化生成步骤
 func VisualizeSchema(next gen.Generator) gen.Generator {
-	return gen.GenerateFunc(func(g *gen.Graph) error {
-		if err := next.Generate(g); err != nil {
-			return err
-		}
-		buf, err := generateHTML(g)
+	return visualizeSchema(newOptions())(next)
+}
+
+// visualizeSchema 返回使用给定配置生成 HTML 页面的钩子。
+func visualizeSchema(o *options) gen.Hook {
+	return func(next gen.Generator) gen.Generator {
+		return gen.GenerateFunc(func(g *gen.Graph) error {
+			if err := checkHighlightPattern(o); err != nil {
+				return err
+			}
+			// 严格模式检查完整的 schema，不受过滤选项影响，并在生成任何代码之前失败。
+			if o.strict {
+				if err := lintError(BuildGraph(g)); err != nil {
+					return err
+				}
+			}
+			if err := next.Generate(g); err != nil {
+				return err
+			}
+			files, err := generateFiles(g, o)
+			if err != nil || o.dryRun {
+				return err
+			}
+			for name, buf := range files {
+				if err := os.WriteFile(filepath.Join(g.Config.Target, name), buf, 0644); err != nil {
+					return err
+				}
+			}
+			// 删除上一次分页生成、这次不再需要的页面，以免被嵌入到 ServeEntviz 中。
+			return removeStalePages(g.Config.Target, "schema-viz.html", files)
+		})
+	}
+}
+
+// servedFormats 是生成的 ServeEntviz 除 HTML 页面外还可以提供的格式及其生成函数，
+// 键为写入目标目录的文件名，这些文件会被嵌入到生成的代码中。
+var servedFormats = map[string]func(*gen.Graph) ([]byte, error){
+	"schema-viz.json": generateManifest,
+	"schema-viz.dot":  GenerateDOT,
+	"schema-viz.mmd":  GenerateMermaid,
+}
+
+// generateFiles 生成钩子需要写入目标目录的全部文件：HTML 页面、servedFormats 中的各种格式以及图 JSON 的 JSON Schema，
+// 返回文件名到内容的映射。
+func generateFiles(g *gen.Graph, o *options) (map[string][]byte, error) {
+	files, err := generatePages(g, o, "schema-viz.html")
+	if err != nil {
+		return nil, err
+	}
+	jsonSchema, err := Asset("graph.schema.json")
+	if err != nil {
+		return nil, err
+	}
+	files["graph.schema.json"] = jsonSchema
+	for name, generate := range servedFormats {
+		buf, err := generate(g)
 		if err != nil {
+			return nil, err
+		}
+		files[name] = buf
+	}
+	return files, nil
+}
+
+// WriteHTML 生成 schema 可视化 HTML 页面并写入 path，
+// 与 VisualizeSchema 钩子在代码生成时所做的工作相同。
+// 对于同一个图，生成的内容是确定的，因此可以用于测试中的 golden 文件。
+//
+// 参数：
+//   - g: 包含 schema 信息的 Ent 生成图
+//   - path: 输出文件路径
+//   - opts: 可选的生成配置项
+//
+// 返回：
+//   - error: 如果生成或写入文件时发生错误则返回错误
+func WriteHTML(g *gen.Graph, path string, opts ...Option) error {
+	return writeHTML(g, path, newOptions(opts...))
+}
+
+// writeHTML 使用给定配置生成 HTML 页面并写入 path。
+func writeHTML(g *gen.Graph, path string, o *options) error {
+	pages, err := generatePages(g, o, filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	for name, buf := range pages {
+		if err := os.WriteFile(filepath.Join(filepath.Dir(path), name), buf, 0644); err != nil {
 			return err
 		}
-		path := filepath.Join(g.Config.Target, "schema-viz.html")
-		return os.WriteFile(path, buf, 0644)
-	})
+	}
+	return removeStalePages(filepath.Dir(path), filepath.Base(path), pages)
 }
 
 // Extension 是 Ent 代码生成器的扩展，用于集成 schema 可视化功能。
@@ -193,28 +1069,47 @@ func VisualizeSchema(next gen.Generator) gen.Generator {
 //
 // 使用方法：
 //   entc.Generate("./ent", entc.Extensions(&entviz.Extension{}))
+//
+// 需要自定义生成行为时，使用 NewExtension 传入 Option：
+//   entc.Generate("./ent", entc.Extensions(entviz.NewExtension(entviz.WithSavedPositions(pos))))
 type Extension struct {
 	entc.DefaultExtension
+	opts []Option
+}
+
+// NewExtension 使用给定的 Option 创建 Extension。
+//
+// 参数：
+//   - opts: 生成配置项
+//
+// 返回：
+//   - *Extension: 可传给 entc.Extensions 的扩展
+func NewExtension(opts ...Option) *Extension {
+	return &Extension{opts: opts}
 }
 
 // Hooks 返回在代码生成过程中执行的钩子列表。
-// 该方法返回 VisualizeSchema 钩子，该钩子会在标准代码生成完成后
-// 自动生成 schema 可视化的 HTML 页面。
+// 该方法返回可视化钩子，该钩子会在标准代码生成完成后
+// 按扩展的配置自动生成 schema 可视化的 HTML 页面。
 //
 // 返回：
-//   - []gen.Hook: 包含 VisualizeSchema 钩子的列表
-func (Extension) Hooks() []gen.Hook {
+//   - []gen.Hook: 包含可视化钩子的列表
+func (e Extension) Hooks() []gen.Hook {
 	return []gen.Hook{
-		VisualizeSchema,
+		visualizeSchema(newOptions(e.opts...)),
 	}
 }
 
 // Templates 返回代码生成过程中使用的模板列表。
-// 该方法返回 entviz.go.tmpl 模板，该模板用于生成辅助代码。
+// 该方法返回 entviz.go.tmpl 模板，该模板用于生成辅助代码；开启 WithDryRun 时不返回任何模板。
 //
 // 返回：
 //   - []*gen.Template: 包含 entviz 模板的列表
-func (Extension) Templates() []*gen.Template {
+func (e Extension) Templates() []*gen.Template {
+	// 空运行不写入页面，嵌入页面的 ServeEntviz 无法编译，因此也不生成。
+	if newOptions(e.opts...).dryRun {
+		return nil
+	}
 	return []*gen.Template{
 		gen.MustParse(gen.NewTemplate("entviz").Parse(tmplfile)),
 	}
@@ -226,17 +1121,44 @@ func (Extension) Templates() []*gen.Template {
 //   - 运行时动态生成可视化
 //   - 测试和调试目的
 //
+// 开启 WithBestEffort 时，如果部分 schema 文件无法编译，会排除这些文件后
+// 生成剩余部分，并在页面顶部列出被排除的文件。
+//
 // 参数：
 //   - schemaPath: Ent schema 文件所在的目录路径
 //   - cfg: Ent 代码生成配置，如果为 nil 则使用默认配置
+//   - opts: 可选的生成配置项
 //
 // 返回：
 //   - []byte: 生成的 HTML 页面字节数组
 //   - error: 如果加载 schema 或生成 HTML 时发生错误则返回错误
-func GeneratePage(schemaPath string, cfg *gen.Config) ([]byte, error) {
+func GeneratePage(schemaPath string, cfg *gen.Config, opts ...Option) ([]byte, error) {
+	o := newOptions(opts...)
 	g, err := entc.LoadGraph(schemaPath, cfg)
 	if err != nil {
-		return nil, err
+		if !o.bestEffort {
+			return nil, err
+		}
+		if g, o.warnings, err = loadPartialGraph(schemaPath, cfg, err); err != nil {
+			return nil, err
+		}
+	}
+	return generateHTML(g, o)
+}
+
+// EntityNames 返回图中所有实体的名称，按字母顺序排序。
+// 可用于校验过滤条件中的实体名称，或按实体逐个生成页面。
+//
+// 参数：
+//   - g: 包含 schema 信息的 Ent 生成图
+//
+// 返回：
+//   - []string: 排序后的实体名称列表
+func EntityNames(g *gen.Graph) []string {
+	names := make([]string, 0, len(g.Nodes))
+	for _, n := range g.Nodes {
+		names = append(names, n.Name)
 	}
-	return generateHTML(g)
+	sort.Strings(names)
+	return names
 }
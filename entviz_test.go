@@ -1,13 +1,60 @@
 package entviz
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
+
+	"entgo.io/ent/dialect/entsql"
+	"entgo.io/ent/entc/gen"
+	"entgo.io/ent/entc/load"
+	"entgo.io/ent/schema"
+	"entgo.io/ent/schema/field"
 )
 
+// newTestGraph 使用 gen.NewGraph 构造一个不依赖 schema 加载的小型图：
+// User 拥有多个 Pet，Pet 通过反向边 owner 指向 User。
+func newTestGraph(t *testing.T, schemas ...*load.Schema) *gen.Graph {
+	t.Helper()
+	user := &load.Schema{
+		Name: "User",
+		Fields: []*load.Field{
+			{Name: "name", Info: &field.TypeInfo{Type: field.TypeString}},
+			{Name: "age", Info: &field.TypeInfo{Type: field.TypeInt}, Optional: true, Comment: "用户年龄"},
+		},
+		Edges: []*load.Edge{
+			{Name: "pets", Type: "Pet"},
+		},
+	}
+	pet := &load.Schema{
+		Name: "Pet",
+		Fields: []*load.Field{
+			{Name: "name", Info: &field.TypeInfo{Type: field.TypeString}},
+		},
+		Edges: []*load.Edge{
+			{Name: "owner", Type: "User", RefName: "pets", Unique: true, Inverse: true},
+		},
+	}
+	g, err := gen.NewGraph(&gen.Config{Package: "example.com/ent", Storage: &gen.Storage{Name: "sql"}}, append([]*load.Schema{user, pet}, schemas...)...)
+	if err != nil {
+		t.Fatalf("Failed to build graph: %v", err)
+	}
+	return g
+}
+
 func TestJsFieldComment(t *testing.T) {
-	field := jsField{
+	field := Field{
 		Name:    "test_field",
 		Type:    "string",
 		Comment: "这是一个测试字段",
@@ -15,7 +62,7 @@ func TestJsFieldComment(t *testing.T) {
 
 	data, err := json.Marshal(field)
 	if err != nil {
-		t.Fatalf("Failed to marshal jsField: %v", err)
+		t.Fatalf("Failed to marshal Field: %v", err)
 	}
 
 	expected := `{"name":"test_field","type":"string","comment":"这是一个测试字段"}`
@@ -23,10 +70,10 @@ func TestJsFieldComment(t *testing.T) {
 		t.Errorf("Expected %s, got %s", expected, string(data))
 	}
 
-	var unmarshaled jsField
+	var unmarshaled Field
 	err = json.Unmarshal(data, &unmarshaled)
 	if err != nil {
-		t.Fatalf("Failed to unmarshal jsField: %v", err)
+		t.Fatalf("Failed to unmarshal Field: %v", err)
 	}
 
 	if unmarshaled.Comment != field.Comment {
@@ -35,7 +82,7 @@ func TestJsFieldComment(t *testing.T) {
 }
 
 func TestJsFieldEmptyComment(t *testing.T) {
-	field := jsField{
+	field := Field{
 		Name:    "test_field",
 		Type:    "string",
 		Comment: "",
@@ -43,7 +90,7 @@ func TestJsFieldEmptyComment(t *testing.T) {
 
 	data, err := json.Marshal(field)
 	if err != nil {
-		t.Fatalf("Failed to marshal jsField: %v", err)
+		t.Fatalf("Failed to marshal Field: %v", err)
 	}
 
 	expected := `{"name":"test_field","type":"string","comment":""}`
@@ -66,3 +113,2267 @@ func TestTemplatePlaceholders(t *testing.T) {
 		t.Error("Template should contain GraphJSON placeholder")
 	}
 }
+
+func TestToJsGraphSavedPositions(t *testing.T) {
+	graph := buildGraph(newTestGraph(t), newOptions(WithSavedPositions(map[string][2]float64{
+		"User": {10, -20},
+	})))
+	if len(graph.Nodes) != 2 {
+		t.Fatalf("Expected 2 nodes, got %d", len(graph.Nodes))
+	}
+	user, pet := graph.Nodes[0], graph.Nodes[1]
+	if user.X == nil || user.Y == nil || *user.X != 10 || *user.Y != -20 {
+		t.Errorf("Expected User at (10, -20), got (%v, %v)", user.X, user.Y)
+	}
+	if pet.X != nil || pet.Y != nil {
+		t.Errorf("Expected Pet without position, got (%v, %v)", pet.X, pet.Y)
+	}
+	if len(graph.Edges) != 1 || graph.Edges[0].Label != "pets" {
+		t.Errorf("Expected only the pets edge, got %+v", graph.Edges)
+	}
+}
+
+func TestToJsGraphEdgeAccessor(t *testing.T) {
+	g := newTestGraph(t)
+	g.Nodes[0].Edges = append(g.Nodes[0].Edges, &gen.Edge{Name: "best_friend", Type: g.Nodes[0], Owner: g.Nodes[0]})
+	graph := buildGraph(g, newOptions())
+	if len(graph.Edges) != 2 {
+		t.Fatalf("Expected 2 edges, got %d", len(graph.Edges))
+	}
+	if got := graph.Edges[0].Accessor; got != "QueryPets" {
+		t.Errorf("Expected accessor QueryPets, got %s", got)
+	}
+	if got := graph.Edges[1].Accessor; got != "QueryBestFriend" {
+		t.Errorf("Expected accessor QueryBestFriend, got %s", got)
+	}
+}
+
+func TestEntityNames(t *testing.T) {
+	names := EntityNames(newTestGraph(t))
+	if strings.Join(names, ",") != "Pet,User" {
+		t.Errorf("Expected sorted names [Pet User], got %v", names)
+	}
+}
+
+func TestShortenType(t *testing.T) {
+	tests := []struct {
+		info     *field.TypeInfo
+		expected string
+	}{
+		{&field.TypeInfo{Type: field.TypeString}, "string"},
+		{&field.TypeInfo{Type: field.TypeInt64}, "int64"},
+		{&field.TypeInfo{Type: field.TypeTime}, "time"},
+		{&field.TypeInfo{Type: field.TypeUUID, Ident: "uuid.UUID"}, "uuid"},
+		{&field.TypeInfo{Type: field.TypeJSON, Ident: "map[string]interface {}"}, "json"},
+		{&field.TypeInfo{Type: field.TypeEnum, Ident: "schema.Role"}, "Role(enum)"},
+		{&field.TypeInfo{Type: field.TypeString, Ident: "*types.Email"}, "*Email(string)"},
+		{&field.TypeInfo{Type: field.TypeOther, Ident: "*schema.Money"}, "*Money"},
+	}
+	for _, tt := range tests {
+		if got := shortenType(tt.info); got != tt.expected {
+			t.Errorf("shortenType(%q): expected %q, got %q", tt.info.String(), tt.expected, got)
+		}
+	}
+}
+
+func TestGenerateMatrix(t *testing.T) {
+	b, err := GenerateMatrix(newTestGraph(t))
+	if err != nil {
+		t.Fatalf("Failed to generate matrix: %v", err)
+	}
+	html := string(b)
+	if !strings.Contains(html, `<th>User</th>
+        <td></td>
+        <td class="rel">pets</td>`) {
+		t.Errorf("Expected User row to mark the pets relation to Pet, got:\n%s", html)
+	}
+	if !strings.Contains(html, `<th>Pet</th>
+        <td></td>
+        <td></td>`) {
+		t.Errorf("Expected Pet row without relations, got:\n%s", html)
+	}
+}
+
+func TestRenderJSON(t *testing.T) {
+	graphJSON, err := json.Marshal(buildGraph(newTestGraph(t), newOptions()))
+	if err != nil {
+		t.Fatalf("Failed to marshal graph: %v", err)
+	}
+	b, err := RenderJSON(graphJSON)
+	if err != nil {
+		t.Fatalf("Failed to render JSON: %v", err)
+	}
+	if !strings.Contains(string(b), string(graphJSON)) {
+		t.Error("Expected rendered page to embed the graph JSON")
+	}
+}
+
+func TestRenderJSONInvalid(t *testing.T) {
+	tests := map[string]string{
+		"malformed":     `{"nodes": [`,
+		"unknown field": `{"vertices": []}`,
+		"missing id":    `{"nodes": [{"fields": []}]}`,
+		"duplicate id":  `{"nodes": [{"id": "User"}, {"id": "User"}]}`,
+		"dangling edge": `{"nodes": [{"id": "User"}], "edges": [{"from": "User", "to": "Pet", "label": "pets"}]}`,
+	}
+	for name, input := range tests {
+		if _, err := RenderJSON([]byte(input)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestGenerateMermaid(t *testing.T) {
+	g := newTestGraph(t)
+	b, err := GenerateMermaid(g)
+	if err != nil {
+		t.Fatalf("Failed to generate mermaid: %v", err)
+	}
+	for _, line := range []string{"erDiagram", `int age "用户年龄"`, `User ||--o{ Pet : "pets"`} {
+		if !strings.Contains(string(b), line) {
+			t.Errorf("Expected mermaid output to contain %q, got:\n%s", line, b)
+		}
+	}
+	g.Nodes[0].Edges[0].Optional = false
+	if b, _ = GenerateMermaid(g); !strings.Contains(string(b), `User ||--|{ Pet : "pets"`) {
+		t.Errorf("Expected required relation notation, got:\n%s", b)
+	}
+	// Mermaid 不识别 \" 和 \n，引号使用实体编码，换行替换为空格。
+	g = newTestGraph(t, &load.Schema{Name: "Note", Fields: []*load.Field{
+		{Name: "text", Info: &field.TypeInfo{Type: field.TypeString}, Comment: "正文，\"引号\"内的内容\n可以换行"},
+	}})
+	if b, _ = GenerateMermaid(g); !strings.Contains(string(b), `string text "正文，#quot;引号#quot;内的内容 可以换行"`) {
+		t.Errorf("Expected escaped comment, got:\n%s", b)
+	}
+}
+
+func TestGenerateDBML(t *testing.T) {
+	g := newTestGraph(t, &load.Schema{
+		Name:  "Group",
+		Edges: []*load.Edge{{Name: "friends", Type: "Group"}},
+	})
+	b, err := GenerateDBML(g)
+	if err != nil {
+		t.Fatalf("Failed to generate DBML: %v", err)
+	}
+	for _, line := range []string{
+		"Table users {\n  id int [pk]\n  name string [not null]\n  age int [null]\n}",
+		"  user_pets int [null]\n",
+		"Table group_friends {\n  group_id int [not null]\n  friend_id int [not null]\n}",
+		"Ref: users.id < pets.user_pets // pets",
+		"Ref: groups.id < group_friends.group_id // friends",
+		"Ref: groups.id < group_friends.friend_id // friends",
+	} {
+		if !strings.Contains(string(b), line) {
+			t.Errorf("Expected DBML output to contain %q, got:\n%s", line, b)
+		}
+	}
+	// 反向边 owner 必填时，外键列不可为空。
+	g.Nodes[1].Edges[0].Optional = false
+	if b, _ = GenerateDBML(g); !strings.Contains(string(b), "  user_pets int [not null]\n") {
+		t.Errorf("Expected non-nullable foreign key, got:\n%s", b)
+	}
+}
+
+func TestToJsGraphShape(t *testing.T) {
+	g := newTestGraph(t,
+		&load.Schema{Name: "Country", Annotations: map[string]any{"EntViz": Shape("ellipse")}},
+		&load.Schema{Name: "Currency", Annotations: map[string]any{"EntViz": Shape("hexagon")}},
+	)
+	graph := buildGraph(g, newOptions())
+	shapes := make(map[string]string)
+	for _, n := range graph.Nodes {
+		shapes[n.ID] = n.Shape
+	}
+	if shapes["Country"] != "ellipse" {
+		t.Errorf("Expected Country to be an ellipse, got %q", shapes["Country"])
+	}
+	if shapes["Currency"] != "" || shapes["User"] != "" {
+		t.Errorf("Expected unsupported and missing shapes to use the default, got %v", shapes)
+	}
+}
+
+func TestToJsGraphDeprecated(t *testing.T) {
+	g := newTestGraph(t,
+		&load.Schema{Name: "Legacy", Annotations: map[string]any{"EntViz": Shape("ellipse").Merge(Deprecated())}},
+	)
+	for _, n := range buildGraph(g, newOptions()).Nodes {
+		if n.Deprecated != (n.ID == "Legacy") {
+			t.Errorf("Unexpected deprecated flag %v for %s", n.Deprecated, n.ID)
+		}
+		if n.ID == "Legacy" && n.Shape != "ellipse" {
+			t.Errorf("Expected merged annotation to keep the shape, got %q", n.Shape)
+		}
+	}
+}
+
+func TestToJsGraphInlineCardinality(t *testing.T) {
+	graph := buildGraph(newTestGraph(t), newOptions(WithInlineCardinality(true)))
+	if got := graph.Edges[0].Label; got != "pets (1:N)" {
+		t.Errorf("Expected label %q, got %q", "pets (1:N)", got)
+	}
+	graph = buildGraph(newTestGraph(t), newOptions())
+	if got := graph.Edges[0].Label; got != "pets" {
+		t.Errorf("Expected label %q, got %q", "pets", got)
+	}
+}
+
+func TestToJsGraphExcludeEdges(t *testing.T) {
+	g := newTestGraph(t, &load.Schema{Name: "Car"})
+	for _, name := range []string{"pets", "owner"} {
+		graph := buildGraph(g, newOptions(WithExcludeEdges([]string{name})))
+		if len(graph.Edges) != 0 {
+			t.Errorf("Excluding %q: expected no edges, got %+v", name, graph.Edges)
+		}
+		if len(graph.Nodes) != 3 {
+			t.Errorf("Excluding %q: expected orphans to be kept, got %d nodes", name, len(graph.Nodes))
+		}
+	}
+	graph := buildGraph(g, newOptions(WithExcludeEdges([]string{"pets"}), WithDropOrphans(true)))
+	if len(graph.Nodes) != 1 || graph.Nodes[0].ID != "Car" {
+		t.Errorf("Expected only the originally isolated Car to remain, got %+v", graph.Nodes)
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	g := newTestGraph(t)
+	path := filepath.Join(t.TempDir(), "schema-viz.html")
+	if err := WriteHTML(g, path); err != nil {
+		t.Fatalf("Failed to write HTML: %v", err)
+	}
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read HTML: %v", err)
+	}
+	expected, err := generateHTML(g, newOptions())
+	if err != nil {
+		t.Fatalf("Failed to generate HTML: %v", err)
+	}
+	if !bytes.Equal(written, expected) {
+		t.Error("Expected written file to match the generated page")
+	}
+}
+
+func TestWriteHTMLPageSize(t *testing.T) {
+	g := newTestGraph(t, &load.Schema{Name: "Group"}, &load.Schema{Name: "Tag"})
+	dir := t.TempDir()
+	// out-3.html 是上一次生成时多出的一页，其他文件只是名称相似。
+	for _, name := range []string{"out-3.html", "out-old.html", "out-03.html"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := WriteHTML(g, filepath.Join(dir, "out.html"), WithPageSize(2)); err != nil {
+		t.Fatalf("Failed to write HTML: %v", err)
+	}
+	for name, exists := range map[string]bool{"out-3.html": false, "out-old.html": true, "out-03.html": true} {
+		if _, err := os.Stat(filepath.Join(dir, name)); (err == nil) != exists {
+			t.Errorf("Expected %s to exist: %v, got %v", name, exists, err)
+		}
+	}
+	first, err := os.ReadFile(filepath.Join(dir, "out.html"))
+	if err != nil {
+		t.Fatalf("Failed to read first page: %v", err)
+	}
+	second, err := os.ReadFile(filepath.Join(dir, "out-2.html"))
+	if err != nil {
+		t.Fatalf("Failed to read second page: %v", err)
+	}
+	if !strings.Contains(string(first), `href="out-2.html"`) || !strings.Contains(string(first), "page 1 of 2") {
+		t.Error("Expected the first page to link to the second page")
+	}
+	if !strings.Contains(string(second), `href="out.html"`) || !strings.Contains(string(second), "page 2 of 2") {
+		t.Error("Expected the second page to link back to the first page")
+	}
+	if !strings.Contains(string(first), `"id":"User"`) || !strings.Contains(string(first), `"id":"Pet"`) {
+		t.Error("Expected related User and Pet on the same page")
+	}
+	if strings.Contains(string(second), `"id":"User"`) {
+		t.Error("Expected User only on the first page")
+	}
+}
+
+func TestToJsGraphDegrees(t *testing.T) {
+	graph := buildGraph(newTestGraph(t), newOptions())
+	user, pet := graph.Nodes[0], graph.Nodes[1]
+	if user.InDegree != 0 || user.OutDegree != 1 {
+		t.Errorf("Expected User ↑0 ↓1, got ↑%d ↓%d", user.InDegree, user.OutDegree)
+	}
+	if pet.InDegree != 1 || pet.OutDegree != 0 {
+		t.Errorf("Expected Pet ↑1 ↓0, got ↑%d ↓%d", pet.InDegree, pet.OutDegree)
+	}
+}
+
+func TestGenerateYAML(t *testing.T) {
+	b, err := GenerateYAML(newTestGraph(t))
+	if err != nil {
+		t.Fatalf("Failed to generate YAML: %v", err)
+	}
+	expected := `entities:
+  - name: User
+    fields:
+      - name: name
+        type: string
+        optional: false
+      - name: age
+        type: int
+        optional: true
+        comment: "用户年龄"
+  - name: Pet
+    fields:
+      - name: name
+        type: string
+        optional: false
+edges:
+  - from: User
+    to: Pet
+    label: pets
+    cardinality: "1:N"
+    required: false
+`
+	if string(b) != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, b)
+	}
+}
+
+func TestBuildGraphMixinNodes(t *testing.T) {
+	timeMixin := func() []*load.Field {
+		return []*load.Field{
+			{Name: "created_at", Info: &field.TypeInfo{Type: field.TypeTime}, Position: &load.Position{MixedIn: true}},
+			{Name: "updated_at", Info: &field.TypeInfo{Type: field.TypeTime}, Position: &load.Position{Index: 1, MixedIn: true}},
+		}
+	}
+	g := newTestGraph(t,
+		&load.Schema{Name: "Car", Fields: append(timeMixin(), &load.Field{Name: "model", Info: &field.TypeInfo{Type: field.TypeString}})},
+		&load.Schema{Name: "Boat", Fields: timeMixin()},
+	)
+	graph := buildGraph(g, newOptions(WithMixinNodes(true)))
+	nodes := make(map[string]Node)
+	for _, n := range graph.Nodes {
+		nodes[n.ID] = n
+	}
+	mixin, ok := nodes["mixin #1"]
+	if !ok || mixin.Kind != "mixin" || len(mixin.Fields) != 2 {
+		t.Fatalf("Expected a mixin node with 2 fields, got %+v", mixin)
+	}
+	if car := nodes["Car"]; len(car.Fields) != 1 || car.Fields[0].Name != "model" {
+		t.Errorf("Expected Car to keep only its own field, got %+v", car.Fields)
+	}
+	if boat := nodes["Boat"]; len(boat.Fields) != 0 {
+		t.Errorf("Expected Boat fields to be extracted, got %+v", boat.Fields)
+	}
+	var includes []string
+	for _, e := range graph.Edges {
+		if e.Kind == "includes" && e.To == "mixin #1" {
+			includes = append(includes, e.From)
+		}
+	}
+	if strings.Join(includes, ",") != "Car,Boat" {
+		t.Errorf("Expected Car and Boat to include the mixin, got %v", includes)
+	}
+}
+
+func TestExportGraphJSONCase(t *testing.T) {
+	g := newTestGraph(t)
+	tests := map[string][]string{
+		"":      {`"inDegree":0`, `"outDegree":1`},
+		"snake": {`"in_degree":0`, `"out_degree":1`},
+		"camel": {`"inDegree":0`, `"outDegree":1`},
+	}
+	for style, keys := range tests {
+		b, err := ExportGraphJSON(g, WithJSONCase(style))
+		if err != nil {
+			t.Fatalf("%q: failed to export JSON: %v", style, err)
+		}
+		for _, key := range keys {
+			if !strings.Contains(string(b), key) {
+				t.Errorf("%q: expected %s in %s", style, key, b)
+			}
+		}
+	}
+	if got := camelCase(snakeCase("outDegree")); got != "outDegree" {
+		t.Errorf("Expected round trip to outDegree, got %s", got)
+	}
+}
+
+func TestBuildGraphThroughNodes(t *testing.T) {
+	g := newTestGraph(t,
+		&load.Schema{Name: "Group", Edges: []*load.Edge{{Name: "members", Type: "User"}}},
+		&load.Schema{Name: "Membership"},
+	)
+	members := g.Nodes[2].Edges[0]
+	members.Rel.Type, members.Through = gen.M2M, g.Nodes[3]
+
+	graph := buildGraph(g, newOptions())
+	if e := graph.Edges[1]; e.From != "Group" || e.To != "User" {
+		t.Errorf("Expected a direct Group -> User edge by default, got %+v", e)
+	}
+	graph = buildGraph(g, newOptions(WithThroughNodes(true)))
+	if len(graph.Edges) != 3 {
+		t.Fatalf("Expected the members edge to be split in two, got %+v", graph.Edges)
+	}
+	if e := graph.Edges[1]; e.From != "Group" || e.To != "Membership" || e.Kind != "through" {
+		t.Errorf("Expected Group -> Membership, got %+v", e)
+	}
+	if e := graph.Edges[2]; e.From != "Membership" || e.To != "User" || e.Kind != "through" {
+		t.Errorf("Expected Membership -> User, got %+v", e)
+	}
+}
+
+func TestGenerateASCII(t *testing.T) {
+	b, err := GenerateASCII(newTestGraph(t))
+	if err != nil {
+		t.Fatalf("Failed to generate ASCII: %v", err)
+	}
+	expected := "User\n" +
+		"  name  string\n" +
+		"  age   int     optional\n" +
+		"  -> pets: Pet (1:N)\n" +
+		"\n" +
+		"Pet\n" +
+		"  name  string\n"
+	if string(b) != expected {
+		t.Errorf("Expected:\n%q\ngot:\n%q", expected, b)
+	}
+}
+
+func TestTopologicalSort(t *testing.T) {
+	graph := Graph{
+		Nodes: []Node{{ID: "Pet"}, {ID: "User"}, {ID: "Toy"}, {ID: "A"}, {ID: "B"}},
+		Edges: []Edge{
+			{From: "User", To: "Pet", Cardinality: "1:N"},
+			{From: "Toy", To: "Pet", Cardinality: "N:1"},
+			{From: "User", To: "User", Cardinality: "1:1"},
+			{From: "A", To: "B", Cardinality: "N:1"},
+			{From: "B", To: "A", Cardinality: "N:1"},
+		},
+	}
+	order, cyclic := TopologicalSort(graph)
+	if got := strings.Join(order, ","); got != "User,Pet,Toy,A,B" {
+		t.Errorf("Expected order User,Pet,Toy,A,B, got %s", got)
+	}
+	if got := strings.Join(cyclic, ","); got != "A,B" {
+		t.Errorf("Expected cyclic A,B, got %s", got)
+	}
+	sorted, cyclic := sortTopologically(graph)
+	if got := strings.Join(cyclic, ","); got != "A,B" {
+		t.Errorf("Expected sortTopologically to return cyclic A,B, got %s", got)
+	}
+	levels := make(map[string]int)
+	for _, n := range sorted.Nodes {
+		levels[n.ID] = *n.Level
+	}
+	if levels["User"] != 0 || levels["Pet"] != 1 || levels["Toy"] != 2 || levels["A"] != 3 {
+		t.Errorf("Unexpected levels %v", levels)
+	}
+	var ids []string
+	for _, n := range sorted.Nodes {
+		ids = append(ids, n.ID)
+	}
+	if got := strings.Join(ids, ","); got != "User,Pet,Toy,A,B" {
+		t.Errorf("Expected nodes in topological order, got %s", got)
+	}
+	// 翻译后 ID 重复的节点不会被丢弃，也不会留下空节点。
+	sorted, _ = sortTopologically(Graph{Nodes: []Node{{ID: "用户", Kind: "mixin"}, {ID: "用户"}}})
+	if len(sorted.Nodes) != 2 || sorted.Nodes[0].Kind != "mixin" || sorted.Nodes[1].ID != "用户" {
+		t.Errorf("Expected both nodes with the same ID to be kept, got %+v", sorted.Nodes)
+	}
+}
+
+func TestFieldCategory(t *testing.T) {
+	tests := map[field.Type]string{
+		field.TypeString:  "string",
+		field.TypeInt64:   "number",
+		field.TypeFloat32: "number",
+		field.TypeBool:    "bool",
+		field.TypeTime:    "time",
+		field.TypeEnum:    "enum",
+		field.TypeUUID:    "uuid",
+		field.TypeJSON:    "json",
+		field.TypeOther:   "other",
+	}
+	for typ, expected := range tests {
+		if got := typeCategory(&field.TypeInfo{Type: typ}); got != expected {
+			t.Errorf("typeCategory(%s): expected %q, got %q", typ, expected, got)
+		}
+	}
+}
+
+func TestFieldDBType(t *testing.T) {
+	g := newTestGraph(t)
+	tests := map[string][2]string{
+		"":         {"varchar(255)", "bigint"},
+		"mysql":    {"varchar(255)", "bigint"},
+		"postgres": {"character varying", "bigint"},
+		"sqlite3":  {"text", "integer"},
+	}
+	for name, expected := range tests {
+		graph := BuildGraph(g, WithDialect(name))
+		fields := graph.Nodes[0].Fields
+		if fields[0].DBType != expected[0] || fields[1].DBType != expected[1] {
+			t.Errorf("WithDialect(%q): expected %v, got %q, %q", name, expected, fields[0].DBType, fields[1].DBType)
+		}
+	}
+}
+
+func TestGenerateHTMLPills(t *testing.T) {
+	g := newTestGraph(t)
+	for _, enabled := range []bool{false, true} {
+		b, err := generateHTML(g, newOptions(WithPills(enabled)))
+		if err != nil {
+			t.Fatalf("Failed to generate HTML: %v", err)
+		}
+		// html/template 会在 JS 值两侧补充空格。
+		if expected := regexp.MustCompile(fmt.Sprintf(`const usePills = \s*%t\s*;`, enabled)); !expected.Match(b) {
+			t.Errorf("Expected page to match %q", expected)
+		}
+	}
+}
+
+func TestFailedFile(t *testing.T) {
+	dir := t.TempDir()
+	user := filepath.Join(dir, "user.go")
+	if err := os.WriteFile(user, []byte("package schema\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]string{
+		user + ":12:3: syntax error: unexpected newline":                     user,
+		"entc/load: " + user + ":3:1: undefined: Pet":                        user,
+		"/elsewhere/user.go:1:1: expected 'package'":                         "",
+		filepath.Join(dir, "missing.go") + ":1:1: no such file or directory": "",
+		"missing go.sum entry for module providing package entgo.io/ent":     "",
+	}
+	for msg, expected := range tests {
+		if got := failedFile(fmt.Errorf("%s", msg), dir); got != expected {
+			t.Errorf("failedFile(%q): expected %q, got %q", msg, expected, got)
+		}
+	}
+}
+
+func TestGenerateHTMLWarnings(t *testing.T) {
+	o := newOptions()
+	b, err := generateHTML(newTestGraph(t), o)
+	if err != nil {
+		t.Fatalf("Failed to generate HTML: %v", err)
+	}
+	if strings.Contains(string(b), `class="banner"`) {
+		t.Error("Expected no warning banner without warnings")
+	}
+	o.warnings = []string{"user.go: syntax error"}
+	if b, err = generateHTML(newTestGraph(t), o); err != nil {
+		t.Fatalf("Failed to generate HTML: %v", err)
+	}
+	if !strings.Contains(string(b), "<li>user.go: syntax error</li>") {
+		t.Error("Expected the warning banner to list user.go")
+	}
+}
+
+func TestWatchDebounce(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "user.go")
+	if err := os.WriteFile(file, []byte("package schema\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	rebuilds := make(chan struct{}, 10)
+	done := make(chan error)
+	go func() {
+		done <- watch(ctx, dir, 50*time.Millisecond, func() error {
+			rebuilds <- struct{}{}
+			return nil
+		})
+	}()
+	<-rebuilds
+	// 连续多次保存只应触发一次重新生成。
+	for i := 1; i <= 3; i++ {
+		if err := os.WriteFile(file, []byte("package schema\n"+strings.Repeat("\n", i)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	select {
+	case <-rebuilds:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a rebuild after the schema changed")
+	}
+	select {
+	case <-rebuilds:
+		t.Error("Expected rapid saves to trigger a single rebuild")
+	case <-time.After(200 * time.Millisecond):
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Expected nil error after cancel, got %v", err)
+	}
+}
+
+func TestBuildGraphIndexNodes(t *testing.T) {
+	tag := &load.Schema{
+		Name: "Tag",
+		Fields: []*load.Field{
+			{Name: "name", Info: &field.TypeInfo{Type: field.TypeString}},
+			{Name: "weight", Info: &field.TypeInfo{Type: field.TypeInt}},
+		},
+		Indexes: []*load.Index{
+			{Fields: []string{"name"}, Unique: true},
+			{Fields: []string{"name", "weight"}},
+		},
+	}
+	g := newTestGraph(t, tag)
+	if graph := BuildGraph(g); len(graph.Nodes) != 3 {
+		t.Fatalf("Expected index nodes to be disabled by default, got %d nodes", len(graph.Nodes))
+	}
+	graph := BuildGraph(g, WithIndexNodes(true))
+	nodes := make(map[string]Node)
+	for _, n := range graph.Nodes {
+		nodes[n.ID] = n
+	}
+	unique, ok := nodes["Tag(name) unique"]
+	if !ok || unique.Kind != "index" || len(unique.Fields) != 1 || unique.Fields[0].Type != "string" {
+		t.Errorf("Unexpected unique index node %+v", unique)
+	}
+	if n, ok := nodes["Tag(name, weight)"]; !ok || len(n.Fields) != 2 {
+		t.Errorf("Expected composite index node, got %+v", graph.Nodes)
+	}
+	var indexEdges int
+	for _, e := range graph.Edges {
+		if e.Kind == "index" && e.From == "Tag" {
+			indexEdges++
+		}
+	}
+	if indexEdges != 2 {
+		t.Errorf("Expected 2 index edges, got %d", indexEdges)
+	}
+}
+
+func TestValidateHTML(t *testing.T) {
+	g := newTestGraph(t)
+	b, err := generateHTML(g, newOptions())
+	if err != nil {
+		t.Fatalf("Failed to generate HTML: %v", err)
+	}
+	if err := ValidateHTML(b, g); err != nil {
+		t.Errorf("Expected generated page to be valid, got %v", err)
+	}
+	if err := ValidateHTML([]byte("<html></html>"), g); err == nil {
+		t.Error("Expected error for page without graph data")
+	}
+	if err := ValidateHTML(b, newTestGraph(t, &load.Schema{Name: "Tag"})); err == nil || !strings.Contains(err.Error(), "Tag") {
+		t.Errorf("Expected missing entity error, got %v", err)
+	}
+}
+
+func TestValidateHTMLOptions(t *testing.T) {
+	g := newTestGraph(t,
+		&load.Schema{Name: "Tag", Pos: "/src/ent/schema/tag.go:12"},
+		&load.Schema{Name: "Group"},
+	)
+	for name, opts := range map[string][]Option{
+		"stable IDs":         {WithStableIDs(true)},
+		"label translations": {WithLabelTranslations(map[string]string{"User": "用户"})},
+		"drop orphans":       {WithExcludeEdges([]string{"pets"}), WithDropOrphans(true)},
+		"connected only":     {WithConnectedOnly(true)},
+	} {
+		b, err := generateHTML(g, newOptions(opts...))
+		if err != nil {
+			t.Fatalf("%s: failed to generate HTML: %v", name, err)
+		}
+		if err := ValidateHTML(b, g, opts...); err != nil {
+			t.Errorf("%s: expected generated page to be valid, got %v", name, err)
+		}
+	}
+	b, err := generateHTML(g, newOptions(WithConnectedOnly(true)))
+	if err != nil {
+		t.Fatalf("Failed to generate HTML: %v", err)
+	}
+	if err := ValidateHTML(b, g); err == nil || !strings.Contains(err.Error(), "Group") {
+		t.Errorf("Expected missing entity error without the generation options, got %v", err)
+	}
+}
+
+func TestValidatePages(t *testing.T) {
+	g := newTestGraph(t, &load.Schema{Name: "Group"}, &load.Schema{Name: "Tag"})
+	files, err := generatePages(g, newOptions(WithPageSize(2)), "out.html")
+	if err != nil {
+		t.Fatalf("Failed to generate pages: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 pages, got %d", len(files))
+	}
+	pages := [][]byte{files["out.html"], files["out-2.html"]}
+	if err := ValidatePages(pages, g, WithPageSize(2)); err != nil {
+		t.Errorf("Expected generated pages to be valid, got %v", err)
+	}
+	if err := ValidateHTML(pages[0], g, WithPageSize(2)); err == nil {
+		t.Error("Expected a single page of a paginated schema to miss entities")
+	}
+}
+
+func TestGenerateHTMLCustomCSS(t *testing.T) {
+	g := newTestGraph(t)
+	css := "#schema { background: black; }"
+	b, err := generateHTML(g, newOptions(WithCustomCSS(css)))
+	if err != nil {
+		t.Fatalf("Failed to generate HTML: %v", err)
+	}
+	page := string(b)
+	i := strings.Index(page, css)
+	if i < 0 {
+		t.Fatal("Expected custom CSS in page")
+	}
+	if base := strings.Index(page, ".var-type"); base > i {
+		t.Error("Expected custom CSS after the default styles")
+	}
+}
+
+func TestGenerateEntityCard(t *testing.T) {
+	g := newTestGraph(t)
+	b, err := GenerateEntityCard(g, "User")
+	if err != nil {
+		t.Fatalf("Failed to generate card: %v", err)
+	}
+	dec := xml.NewDecoder(bytes.NewReader(b))
+	var texts []string
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Expected well-formed SVG: %v", err)
+		}
+		if c, ok := tok.(xml.CharData); ok && strings.TrimSpace(string(c)) != "" {
+			texts = append(texts, string(c))
+		}
+	}
+	if expected := []string{"User", "name", "string", "age", "int"}; strings.Join(texts, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected card texts %v, got %v", expected, texts)
+	}
+	if _, err := GenerateEntityCard(g, "Unknown"); err == nil {
+		t.Error("Expected error for unknown entity")
+	}
+}
+
+func TestGenerateHTMLCollapsed(t *testing.T) {
+	g := newTestGraph(t)
+	for _, enabled := range []bool{false, true} {
+		b, err := generateHTML(g, newOptions(WithCollapsed(enabled)))
+		if err != nil {
+			t.Fatalf("Failed to generate HTML: %v", err)
+		}
+		if expected := regexp.MustCompile(fmt.Sprintf(`const collapsed = \s*%t\s*;`, enabled)); !expected.Match(b) {
+			t.Errorf("Expected page to match %q", expected)
+		}
+		// 折叠时字段数据仍然完整地嵌入在页面中。
+		if !bytes.Contains(b, []byte(`"comment":"用户年龄"`)) {
+			t.Error("Expected field data to be embedded")
+		}
+	}
+}
+
+func TestBuildGraphBidirectional(t *testing.T) {
+	g := newTestGraph(t, &load.Schema{
+		Name:  "Tag",
+		Edges: []*load.Edge{{Name: "users", Type: "User"}},
+	})
+	graph := BuildGraph(g)
+	bidi := make(map[string]bool)
+	for _, e := range graph.Edges {
+		bidi[e.From+"."+e.Label] = e.Bidirectional
+	}
+	if len(bidi) != 2 {
+		t.Fatalf("Expected inverse edges to be merged, got %+v", graph.Edges)
+	}
+	if !bidi["User.pets"] {
+		t.Error("Expected User.pets to be bidirectional")
+	}
+	if bidi["Tag.users"] {
+		t.Error("Expected Tag.users without inverse to be one-directional")
+	}
+}
+
+func TestGenerateHTMLMaxFields(t *testing.T) {
+	g := newTestGraph(t)
+	b, err := generateHTML(g, newOptions(WithMaxFields(1)))
+	if err != nil {
+		t.Fatalf("Failed to generate HTML: %v", err)
+	}
+	if expected := regexp.MustCompile(`const maxFields = \s*1\s*;`); !expected.Match(b) {
+		t.Errorf("Expected page to match %q", expected)
+	}
+	// 节点只截断显示，嵌入的数据中保留全部字段。
+	if !bytes.Contains(b, []byte(`"name":"age"`)) {
+		t.Error("Expected all fields to be embedded")
+	}
+}
+
+func TestGenerateRelationshipsCSV(t *testing.T) {
+	b, err := GenerateRelationshipsCSV(newTestGraph(t))
+	if err != nil {
+		t.Fatalf("Failed to generate CSV: %v", err)
+	}
+	expected := "from,to,label,cardinality,required\nUser,Pet,pets,1:N,false\n"
+	if string(b) != expected {
+		t.Errorf("Expected CSV:\n%s\ngot:\n%s", expected, b)
+	}
+}
+
+func TestGenerateNotionCSV(t *testing.T) {
+	b, err := GenerateNotionCSV(newTestGraph(t))
+	if err != nil {
+		t.Fatalf("Failed to generate CSV: %v", err)
+	}
+	expected := "Entity,Field,Type,Comment\nUser,name,string,\nUser,age,int,用户年龄\nPet,name,string,\n"
+	if string(b) != expected {
+		t.Errorf("Expected CSV:\n%s\ngot:\n%s", expected, b)
+	}
+}
+
+func TestBuildGraphClientHints(t *testing.T) {
+	g := newTestGraph(t)
+	if n := BuildGraph(g).Nodes[0]; n.Client != "" {
+		t.Errorf("Expected no client hint by default, got %q", n.Client)
+	}
+	if n := BuildGraph(g, WithClientHints(true)).Nodes[0]; n.Client != "client.User" {
+		t.Errorf("Expected client hint client.User, got %q", n.Client)
+	}
+}
+
+func TestExportGraphJSONStream(t *testing.T) {
+	g := newTestGraph(t)
+	var b bytes.Buffer
+	if err := ExportGraphJSONStream(&b, g); err != nil {
+		t.Fatalf("Failed to stream graph JSON: %v", err)
+	}
+	var streamed Graph
+	if err := json.Unmarshal(b.Bytes(), &streamed); err != nil {
+		t.Fatalf("Expected valid JSON: %v\n%s", err, b.String())
+	}
+	expected, err := json.Marshal(BuildGraph(g))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := json.Marshal(streamed); !bytes.Equal(got, expected) {
+		t.Errorf("Expected streamed graph to match BuildGraph:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestExportGraphJSONStreamMatchesExport(t *testing.T) {
+	for name, g := range map[string]*gen.Graph{
+		"schema": newTestGraph(t),
+		"empty":  {Config: &gen.Config{Package: "example.com/ent"}},
+	} {
+		var b bytes.Buffer
+		if err := ExportGraphJSONStream(&b, g); err != nil {
+			t.Fatalf("%s: failed to stream graph JSON: %v", name, err)
+		}
+		var streamed bytes.Buffer
+		if err := json.Compact(&streamed, b.Bytes()); err != nil {
+			t.Fatalf("%s: expected valid JSON: %v\n%s", name, err, b.String())
+		}
+		expected, err := ExportGraphJSON(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(streamed.Bytes(), expected) {
+			t.Errorf("%s: expected streamed JSON to match ExportGraphJSON:\n%s\ngot:\n%s", name, expected, streamed.Bytes())
+		}
+	}
+}
+
+func TestGenerateHTMLDeterministic(t *testing.T) {
+	g := newTestGraph(t)
+	first, err := generateHTML(g, newOptions(WithDeterministic(true)))
+	if err != nil {
+		t.Fatalf("Failed to generate HTML: %v", err)
+	}
+	second, err := generateHTML(newTestGraph(t), newOptions(WithDeterministic(true)))
+	if err != nil {
+		t.Fatalf("Failed to generate HTML: %v", err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Error("Expected identical pages across runs")
+	}
+	if expected := regexp.MustCompile(`const deterministic = \s*true\s*;`); !expected.Match(first) {
+		t.Errorf("Expected page to match %q", expected)
+	}
+}
+
+func TestNodeColor(t *testing.T) {
+	if nodeColor("User") != nodeColor("User") {
+		t.Error("Expected the same color for the same entity")
+	}
+	if nodeColor("User") == nodeColor("Pet") {
+		t.Error("Expected different colors for different entities")
+	}
+	if c := nodeColor("User"); !regexp.MustCompile(`^#[0-9a-f]{6}$`).MatchString(c) {
+		t.Errorf("Expected #rrggbb color, got %q", c)
+	}
+	if n := BuildGraph(newTestGraph(t)).Nodes[0]; n.Color != nodeColor("User") {
+		t.Errorf("Expected node color %q, got %q", nodeColor("User"), n.Color)
+	}
+	if r, g, b := hslToRGB(0, 1, 0.5); r != 255 || g != 0 || b != 0 {
+		t.Errorf("Expected pure red, got %d,%d,%d", r, g, b)
+	}
+}
+
+func TestBuildGraphUpdateDefault(t *testing.T) {
+	g := newTestGraph(t, &load.Schema{
+		Name: "Post",
+		Fields: []*load.Field{
+			{Name: "created_at", Info: &field.TypeInfo{Type: field.TypeTime}, Default: true},
+			{Name: "updated_at", Info: &field.TypeInfo{Type: field.TypeTime}, Default: true, UpdateDefault: true},
+		},
+	})
+	for _, n := range BuildGraph(g).Nodes {
+		if n.ID != "Post" {
+			continue
+		}
+		if n.Fields[0].UpdateDefault || !n.Fields[1].UpdateDefault {
+			t.Errorf("Expected only updated_at to be marked, got %+v", n.Fields)
+		}
+	}
+}
+
+func TestGenerateExcalidraw(t *testing.T) {
+	b, err := GenerateExcalidraw(newTestGraph(t))
+	if err != nil {
+		t.Fatalf("Failed to generate Excalidraw: %v", err)
+	}
+	var file excalidrawFile
+	if err := json.Unmarshal(b, &file); err != nil {
+		t.Fatalf("Expected valid JSON: %v", err)
+	}
+	if file.Type != "excalidraw" {
+		t.Errorf("Expected excalidraw file type, got %q", file.Type)
+	}
+	types := make(map[string]int)
+	ids := make(map[string]bool)
+	for _, el := range file.Elements {
+		types[el.Type]++
+		ids[el.ID] = true
+	}
+	// 2 个实体矩形及其文本，1 条关系箭头及其标签。
+	if types["rectangle"] != 2 || types["text"] != 3 || types["arrow"] != 1 {
+		t.Errorf("Unexpected element counts %v", types)
+	}
+	for _, el := range file.Elements {
+		if el.Type == "arrow" && (!ids[el.StartBinding.ElementID] || !ids[el.EndBinding.ElementID]) {
+			t.Errorf("Arrow %s is bound to unknown elements", el.ID)
+		}
+	}
+}
+
+func TestGenerateHTMLNodeSize(t *testing.T) {
+	g := newTestGraph(t)
+	tests := []struct {
+		opts            []Option
+		min, max, level int
+	}{
+		{nil, 60, 60, 250},
+		{[]Option{WithNodeSize(80, 200), WithSpacing(400, 150)}, 80, 200, 400},
+	}
+	for _, tt := range tests {
+		b, err := generateHTML(g, newOptions(tt.opts...))
+		if err != nil {
+			t.Fatalf("Failed to generate HTML: %v", err)
+		}
+		if expected := regexp.MustCompile(fmt.Sprintf(`nodeWidth = \{ minimum: \s*%d\s*, maximum: \s*%d\s* \}`, tt.min, tt.max)); !expected.Match(b) {
+			t.Errorf("Expected page to match %q", expected)
+		}
+		if expected := regexp.MustCompile(fmt.Sprintf(`levelSeparation: \s*%d\s*,`, tt.level)); !expected.Match(b) {
+			t.Errorf("Expected page to match %q", expected)
+		}
+	}
+}
+
+func TestAsset(t *testing.T) {
+	for _, name := range []string{"fira_code.css", "vis-network.min.js", "randomcolor.min.js"} {
+		b, err := Asset(name)
+		if err != nil || len(b) == 0 {
+			t.Errorf("Expected asset %s, got %d bytes, error %v", name, len(b), err)
+		}
+	}
+	for _, name := range []string{"missing.js", "../viz.tmpl", "assets/fira_code.css"} {
+		if _, err := Asset(name); err == nil {
+			t.Errorf("Expected error for asset %q", name)
+		}
+	}
+}
+
+func TestBuildGraphEmbedEdges(t *testing.T) {
+	str := &field.TypeInfo{Type: field.TypeString}
+	g := newTestGraph(t,
+		&load.Schema{Name: "Account", Fields: []*load.Field{{Name: "email", Info: str}, {Name: "password", Info: str}}},
+		&load.Schema{Name: "Admin", Fields: []*load.Field{{Name: "email", Info: str}, {Name: "password", Info: str}, {Name: "role", Info: str}}},
+	)
+	if graph := BuildGraph(g); len(graph.Edges) != 1 {
+		t.Fatalf("Expected embed edges to be disabled by default, got %+v", graph.Edges)
+	}
+	var embeds []Edge
+	for _, e := range BuildGraph(g, WithEmbedEdges(true)).Edges {
+		if e.Kind == "embed" {
+			embeds = append(embeds, e)
+		}
+	}
+	// User(name, age) 包含 Pet(name)，但 Pet 的字段太少，不应视为嵌入。
+	if len(embeds) != 1 || embeds[0].From != "Admin" || embeds[0].To != "Account" {
+		t.Errorf("Expected only Admin to embed Account, got %+v", embeds)
+	}
+}
+
+func TestGenerateCytoscapeJSON(t *testing.T) {
+	b, err := GenerateCytoscapeJSON(newTestGraph(t))
+	if err != nil {
+		t.Fatalf("Failed to generate Cytoscape JSON: %v", err)
+	}
+	var out cytoscapeGraph
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatalf("Expected valid JSON: %v", err)
+	}
+	if len(out.Elements.Nodes) != 2 || out.Elements.Nodes[0].Data.ID != "User" || len(out.Elements.Nodes[0].Data.Fields) != 2 {
+		t.Errorf("Unexpected nodes %+v", out.Elements.Nodes)
+	}
+	if len(out.Elements.Edges) != 1 {
+		t.Fatalf("Expected 1 edge, got %+v", out.Elements.Edges)
+	}
+	if e := out.Elements.Edges[0].Data; e.Source != "User" || e.Target != "Pet" || e.Cardinality != "1:N" || e.ID == "" {
+		t.Errorf("Unexpected edge %+v", e)
+	}
+}
+
+func TestExportTopologyJSON(t *testing.T) {
+	b, err := ExportTopologyJSON(newTestGraph(t))
+	if err != nil {
+		t.Fatalf("Failed to export topology: %v", err)
+	}
+	expected := `{"nodes":["User","Pet"],"edges":[{"from":"User","to":"Pet","label":"pets"}]}`
+	if string(b) != expected {
+		t.Errorf("Expected %s, got %s", expected, b)
+	}
+}
+
+func TestBuildGraphFieldAnnotations(t *testing.T) {
+	g := newTestGraph(t, &load.Schema{
+		Name: "Post",
+		Fields: []*load.Field{
+			{
+				Name:        "title",
+				Info:        &field.TypeInfo{Type: field.TypeString},
+				Tag:         `validate:"required"`,
+				Annotations: map[string]any{"EntGQL": map[string]any{"OrderField": "TITLE"}},
+			},
+			{Name: "body", Info: &field.TypeInfo{Type: field.TypeString}},
+		},
+	})
+	for _, n := range BuildGraph(g, WithFieldAnnotations([]string{"EntGQL", "validate"})).Nodes {
+		if n.ID != "Post" {
+			continue
+		}
+		if expected := []string{"EntGQL", `validate:"required"`}; fmt.Sprint(n.Fields[0].Tags) != fmt.Sprint(expected) {
+			t.Errorf("Expected tags %q, got %q", expected, n.Fields[0].Tags)
+		}
+		if n.Fields[1].Tags != nil {
+			t.Errorf("Expected no tags on body, got %q", n.Fields[1].Tags)
+		}
+	}
+}
+
+func TestDiffGraphs(t *testing.T) {
+	old := newTestGraph(t, &load.Schema{Name: "Tag"})
+	str := &field.TypeInfo{Type: field.TypeString}
+	new := newTestGraph(t, &load.Schema{Name: "Car", Fields: []*load.Field{{Name: "model", Info: str}}})
+	// 修改 User.age 的类型，删除 Pet.name。
+	new.Nodes[0].Fields[1].Type = &field.TypeInfo{Type: field.TypeFloat64}
+	new.Nodes[1].Fields = nil
+
+	merged := diffGraphs(BuildGraph(old), BuildGraph(new))
+	nodes := make(map[string]Node)
+	for _, n := range merged.Nodes {
+		nodes[n.ID] = n
+	}
+	if nodes["Car"].Diff != diffAdded || nodes["Tag"].Diff != diffRemoved || nodes["User"].Diff != diffChanged || nodes["Pet"].Diff != diffChanged {
+		t.Errorf("Unexpected node diffs %+v", merged.Nodes)
+	}
+	if f := nodes["User"].Fields; f[0].Diff != "" || f[1].Diff != diffChanged {
+		t.Errorf("Unexpected User field diffs %+v", f)
+	}
+	if f := nodes["Pet"].Fields; len(f) != 1 || f[0].Diff != diffRemoved {
+		t.Errorf("Expected removed Pet.name, got %+v", f)
+	}
+	if len(merged.Edges) != 1 || merged.Edges[0].Diff != "" {
+		t.Errorf("Expected unchanged pets edge, got %+v", merged.Edges)
+	}
+
+	b, err := RenderDiffHTML(old, new)
+	if err != nil {
+		t.Fatalf("Failed to render diff: %v", err)
+	}
+	if err := ValidateHTML(b, new); err != nil {
+		t.Errorf("Expected valid diff page: %v", err)
+	}
+}
+
+func TestBuildGraphTreeSelfRefs(t *testing.T) {
+	g := newTestGraph(t, &load.Schema{
+		Name: "Employee",
+		Edges: []*load.Edge{
+			{Name: "reports", Type: "Employee"},
+			{Name: "manager", Type: "Employee", RefName: "reports", Unique: true, Inverse: true},
+			{Name: "peers", Type: "Employee"},
+		},
+	})
+	tree := make(map[string]bool)
+	for _, e := range BuildGraph(g, WithTreeSelfRefs(true)).Edges {
+		tree[e.Label] = e.Tree
+	}
+	// reports 与 manager 构成一对多的树形结构，peers 是多对多的双向关系。
+	if !tree["reports"] || tree["peers"] || tree["pets"] {
+		t.Errorf("Unexpected tree flags %v", tree)
+	}
+	for _, e := range BuildGraph(g).Edges {
+		if e.Tree {
+			t.Errorf("Expected tree flags to be disabled by default, got %+v", e)
+		}
+	}
+}
+
+func TestGenerateHTMLPackageHeader(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("// service\nmodule example.com\n\ngo 1.24\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(dir, "ent")
+	if err := os.Mkdir(target, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := modulePath(target); got != "example.com" {
+		t.Errorf("Expected module example.com, got %q", got)
+	}
+	g := newTestGraph(t)
+	g.Config.Target = target
+	o := newOptions()
+	b, err := generateHTML(g, o)
+	if err != nil {
+		t.Fatalf("Failed to generate HTML: %v", err)
+	}
+	if !bytes.Contains(b, []byte(`example.com/ent <span class="module">(module example.com)</span>`)) {
+		t.Error("Expected package and module header in page")
+	}
+	// 包路径只属于本次生成的页面，复用同一份配置渲染其他图时不会带上。
+	if b, err = renderHTML(BuildGraph(g), o); err != nil {
+		t.Fatalf("Failed to render HTML: %v", err)
+	}
+	if bytes.Contains(b, []byte(`(module example.com)`)) {
+		t.Error("Expected generateHTML to leave the options unchanged")
+	}
+}
+
+func TestBuildGraphRelations(t *testing.T) {
+	graph := BuildGraph(newTestGraph(t))
+	rels := make(map[string][]string)
+	for _, n := range graph.Nodes {
+		rels[n.ID] = n.Relations
+	}
+	if expected := []string{"pets → Pet (1:N)"}; fmt.Sprint(rels["User"]) != fmt.Sprint(expected) {
+		t.Errorf("Expected User relations %q, got %q", expected, rels["User"])
+	}
+	if expected := []string{"User.pets ← (N:1)"}; fmt.Sprint(rels["Pet"]) != fmt.Sprint(expected) {
+		t.Errorf("Expected Pet relations %q, got %q", expected, rels["Pet"])
+	}
+}
+
+func TestVisualizeSchemaStrict(t *testing.T) {
+	g := newTestGraph(t, &load.Schema{Name: "Tag"})
+	g.Config.Target = t.TempDir()
+	noop := gen.GenerateFunc(func(*gen.Graph) error { return nil })
+	if err := visualizeSchema(newOptions())(noop).Generate(g); err != nil {
+		t.Fatalf("Expected no error without strict mode, got %v", err)
+	}
+	generated := false
+	next := gen.GenerateFunc(func(*gen.Graph) error {
+		generated = true
+		return nil
+	})
+	err := visualizeSchema(newOptions(WithStrict(true), WithConnectedOnly(true)))(next).Generate(g)
+	if err == nil {
+		t.Fatal("Expected strict mode to fail")
+	}
+	if generated {
+		t.Error("Expected strict mode to fail before Ent code generation")
+	}
+	for _, expected := range []string{"field User.name has no comment", "entity Tag has no relationships"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error to contain %q, got %v", expected, err)
+		}
+	}
+	if strings.Contains(err.Error(), "User.age") {
+		t.Errorf("Expected commented field to pass, got %v", err)
+	}
+}
+
+func TestGenerateStructurizr(t *testing.T) {
+	b, err := GenerateStructurizr(newTestGraph(t))
+	if err != nil {
+		t.Fatalf("Failed to generate Structurizr DSL: %v", err)
+	}
+	for _, expected := range []string{
+		`entviz_system = softwareSystem "ent" {`,
+		`user = container "User" "name: string, age: int" "Ent entity"`,
+		`pet = container "Pet" "name: string" "Ent entity"`,
+		`user -> pet "pets (1:N)"`,
+		`container entviz_system {`,
+	} {
+		if !bytes.Contains(b, []byte(expected)) {
+			t.Errorf("Expected DSL to contain %q, got:\n%s", expected, b)
+		}
+	}
+	// user_group 和 UserGroup 的标识符相同，第二个加上数字后缀。
+	b, err = GenerateStructurizr(newTestGraph(t, &load.Schema{Name: "user_group"}, &load.Schema{Name: "UserGroup"}))
+	if err != nil {
+		t.Fatalf("Failed to generate Structurizr DSL: %v", err)
+	}
+	for _, expected := range []string{`user_group = container "user_group"`, `user_group_2 = container "UserGroup"`} {
+		if !bytes.Contains(b, []byte(expected)) {
+			t.Errorf("Expected DSL to contain %q, got:\n%s", expected, b)
+		}
+	}
+}
+
+func TestBuildGraphComponents(t *testing.T) {
+	g := newTestGraph(t,
+		&load.Schema{Name: "Audit", Edges: []*load.Edge{{Name: "entries", Type: "Entry"}}},
+		&load.Schema{Name: "Tag"},
+		&load.Schema{Name: "Entry"},
+	)
+	var order []string
+	components := make(map[string]int)
+	for _, n := range BuildGraph(g, WithComponents(true)).Nodes {
+		order = append(order, n.ID)
+		components[n.ID] = n.Component
+	}
+	if expected := "[User Pet Audit Entry Tag]"; fmt.Sprint(order) != expected {
+		t.Errorf("Expected nodes grouped as %s, got %v", expected, order)
+	}
+	if components["User"] != components["Pet"] || components["Audit"] != components["Entry"] || components["Audit"] == components["Tag"] {
+		t.Errorf("Unexpected components %v", components)
+	}
+}
+
+func TestGenerateHTMLArrowStyles(t *testing.T) {
+	b, err := generateHTML(newTestGraph(t), newOptions(WithArrowStyles(map[string]string{"1:1": "diamond"})))
+	if err != nil {
+		t.Fatalf("Failed to generate HTML: %v", err)
+	}
+	m := regexp.MustCompile(`const arrowStyles = (.*);`).FindSubmatch(b)
+	if m == nil {
+		t.Fatal("Expected arrow styles in page")
+	}
+	var styles map[string]string
+	if err := json.Unmarshal(m[1], &styles); err != nil {
+		t.Fatalf("Expected arrow styles to be JSON: %v", err)
+	}
+	if styles["1:1"] != "diamond" || styles["1:N"] != "crow" {
+		t.Errorf("Expected overridden 1:1 and default 1:N styles, got %v", styles)
+	}
+}
+
+func TestGraphFromJSON(t *testing.T) {
+	g := newTestGraph(t)
+	expected := BuildGraph(g, WithClientHints(true))
+	for _, style := range []string{"", "snake", "camel"} {
+		data, err := ExportGraphJSON(g, WithClientHints(true), WithJSONCase(style))
+		if err != nil {
+			t.Fatalf("Failed to export graph JSON: %v", err)
+		}
+		graph, err := GraphFromJSON(data)
+		if err != nil {
+			t.Fatalf("Failed to read graph JSON with case %q: %v", style, err)
+		}
+		if !reflect.DeepEqual(graph, expected) {
+			t.Errorf("Expected round trip with case %q to be equal:\n%+v\ngot:\n%+v", style, expected, graph)
+		}
+	}
+	if _, err := GraphFromJSON([]byte(`{"nodes":[],"edges":[{"from":"A","to":"B"}]}`)); err == nil {
+		t.Error("Expected error for dangling edge")
+	}
+}
+
+func TestBuildGraphFieldReferences(t *testing.T) {
+	g := newTestGraph(t,
+		&load.Schema{
+			Name:  "Chauffeur",
+			Edges: []*load.Edge{{Name: "cars", Type: "Car"}},
+		},
+		&load.Schema{
+			Name: "Car",
+			Fields: []*load.Field{
+				{Name: "model", Info: &field.TypeInfo{Type: field.TypeString}},
+				{Name: "chauffeur_id", Info: &field.TypeInfo{Type: field.TypeInt}, Optional: true},
+			},
+			Edges: []*load.Edge{
+				{Name: "chauffeur", Type: "Chauffeur", RefName: "cars", Unique: true, Inverse: true, Field: "chauffeur_id"},
+			},
+		},
+	)
+	for _, n := range BuildGraph(g).Nodes {
+		if n.ID != "Car" {
+			continue
+		}
+		if n.Fields[0].References != "" || n.Fields[1].References != "Chauffeur.id" {
+			t.Errorf("Expected only chauffeur_id to reference Chauffeur.id, got %+v", n.Fields)
+		}
+	}
+}
+
+func TestLayout(t *testing.T) {
+	g := newTestGraph(t, &load.Schema{Name: "Tag"})
+	positions := Layout(g)
+	if len(positions) != 3 {
+		t.Fatalf("Expected a position for each entity, got %v", positions)
+	}
+	// Pet 依赖 User，位于下一层。
+	if positions["Pet"][1] <= positions["User"][1] {
+		t.Errorf("Expected Pet below User, got %v", positions)
+	}
+	if positions["Tag"][1] != positions["User"][1] || positions["Tag"][0] == positions["User"][0] {
+		t.Errorf("Expected Tag next to User on the first layer, got %v", positions)
+	}
+	if !reflect.DeepEqual(positions, Layout(newTestGraph(t, &load.Schema{Name: "Tag"}))) {
+		t.Error("Expected identical positions across runs")
+	}
+}
+
+func TestBundleEdges(t *testing.T) {
+	edges := []Edge{
+		{From: "User", To: "Post", Label: "posts"},
+		{From: "User", To: "Pet", Label: "pets"},
+		{From: "User", To: "Post", Label: "posts"},
+		{From: "Post", To: "User", Label: "posts"},
+		{From: "User", To: "Post", Label: "posts"},
+	}
+	got := bundleEdges(edges)
+	want := []Edge{
+		{From: "User", To: "Post", Label: "posts (×3)"},
+		{From: "User", To: "Pet", Label: "pets"},
+		{From: "Post", To: "User", Label: "posts"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestGenerateHTMLTitle(t *testing.T) {
+	g := newTestGraph(t)
+	b, err := generateHTML(g, newOptions(WithTitle("Billing Service Schema — v2.3"), WithDescription("Invoices & payments")))
+	if err != nil {
+		t.Fatalf("Failed to generate HTML: %v", err)
+	}
+	page := string(b)
+	if !strings.Contains(page, `<h1 class="title">Billing Service Schema — v2.3</h1>`) {
+		t.Error("Expected title header in page")
+	}
+	if !strings.Contains(page, `<p class="description">Invoices &amp; payments</p>`) {
+		t.Error("Expected escaped description in page")
+	}
+	b, err = generateHTML(g, newOptions())
+	if err != nil {
+		t.Fatalf("Failed to generate HTML: %v", err)
+	}
+	if strings.Contains(string(b), `class="title"`) || strings.Contains(string(b), `class="description"`) {
+		t.Error("Expected no header without title and description")
+	}
+}
+
+func TestBuildGraphEnumNodes(t *testing.T) {
+	status := []struct{ N, V string }{{"active", "active"}, {"inactive", "inactive"}}
+	// level 通过 GoType 使用同一个 Go 类型，是共享的枚举；status 只是同名的字段。
+	level := &field.TypeInfo{Type: field.TypeEnum, Ident: "schema.Level", PkgPath: "example.com/schema", RType: &field.RType{
+		Name: "Level", Ident: "schema.Level", Kind: reflect.String, PkgPath: "example.com/schema",
+	}}
+	levels := []struct{ N, V string }{{"low", "low"}, {"high", "high"}}
+	g := newTestGraph(t,
+		&load.Schema{Name: "Account", Fields: []*load.Field{
+			{Name: "status", Info: &field.TypeInfo{Type: field.TypeEnum}, Enums: status},
+			{Name: "plan", Info: &field.TypeInfo{Type: field.TypeEnum}, Enums: []struct{ N, V string }{{"free", "free"}, {"pro", "pro"}}},
+			{Name: "level", Info: level, Enums: levels},
+		}},
+		&load.Schema{Name: "Device", Fields: []*load.Field{
+			{Name: "status", Info: &field.TypeInfo{Type: field.TypeEnum}, Enums: []struct{ N, V string }{{"inactive", "inactive"}, {"active", "active"}}},
+			{Name: "priority", Info: level, Enums: levels},
+		}},
+	)
+	graph := BuildGraph(g, WithEnumNodes(true))
+	var enums []string
+	for _, n := range graph.Nodes {
+		if n.Kind == "enum" {
+			enums = append(enums, n.ID+" ("+n.Label+")")
+		}
+	}
+	want := []string{"Account.status enum (status enum)", "Account.plan enum (plan enum)", "Level enum ()", "Device.status enum (status enum)"}
+	if !reflect.DeepEqual(enums, want) {
+		t.Fatalf("Expected enum nodes %v, got %v", want, enums)
+	}
+	for _, n := range graph.Nodes {
+		if n.ID == "Account.status enum" && (len(n.Fields) != 2 || n.Fields[0].Name != "active") {
+			t.Errorf("Expected enum values as fields, got %v", n.Fields)
+		}
+	}
+	var uses []string
+	for _, e := range graph.Edges {
+		if e.Kind == "uses" {
+			uses = append(uses, e.From+"."+e.Label+" → "+e.To)
+		}
+	}
+	want = []string{
+		"Account.status → Account.status enum", "Account.plan → Account.plan enum",
+		"Account.level → Level enum", "Device.priority → Level enum", "Device.status → Device.status enum",
+	}
+	if !reflect.DeepEqual(uses, want) {
+		t.Errorf("Expected uses edges %v, got %v", want, uses)
+	}
+	for _, n := range BuildGraph(g).Nodes {
+		if n.Kind == "enum" {
+			t.Error("Expected no enum nodes by default")
+		}
+	}
+}
+
+func TestGenerateDOT(t *testing.T) {
+	b, err := GenerateDOT(newTestGraph(t))
+	if err != nil {
+		t.Fatalf("Failed to generate DOT: %v", err)
+	}
+	dot := string(b)
+	for _, expected := range []string{
+		`"User" [label="{User|name: string\lage: int\l}"];`,
+		`"User" -> "Pet" [label="pets (1:N)"];`,
+	} {
+		if !strings.Contains(dot, expected) {
+			t.Errorf("Expected DOT to contain %s, got:\n%s", expected, dot)
+		}
+	}
+}
+
+func TestVisualizeSchemaFormats(t *testing.T) {
+	g := newTestGraph(t)
+	g.Config.Target = t.TempDir()
+	noop := gen.GenerateFunc(func(*gen.Graph) error { return nil })
+	if err := visualizeSchema(newOptions())(noop).Generate(g); err != nil {
+		t.Fatalf("Failed to run hook: %v", err)
+	}
+	for _, name := range []string{"schema-viz.html", "schema-viz.json", "schema-viz.dot", "schema-viz.mmd", "graph.schema.json"} {
+		if _, err := os.Stat(filepath.Join(g.Config.Target, name)); err != nil {
+			t.Errorf("Expected %s to be written: %v", name, err)
+		}
+	}
+}
+
+func TestBuildGraphSoftDelete(t *testing.T) {
+	g := newTestGraph(t, &load.Schema{Name: "Post", Fields: []*load.Field{
+		{Name: "deleted_at", Info: &field.TypeInfo{Type: field.TypeTime}, Optional: true},
+	}})
+	graph := BuildGraph(g, WithSoftDeleteField("deleted_at"))
+	for _, n := range graph.Nodes {
+		if n.SoftDelete != (n.ID == "Post") {
+			t.Errorf("Unexpected soft-delete flag %v for %s", n.SoftDelete, n.ID)
+		}
+	}
+	for _, n := range BuildGraph(g).Nodes {
+		if n.SoftDelete {
+			t.Errorf("Expected no soft-delete detection by default, got %s", n.ID)
+		}
+	}
+}
+
+func TestBuildGraphConnectedOnly(t *testing.T) {
+	g := newTestGraph(t, &load.Schema{Name: "Country"})
+	graph := BuildGraph(g, WithConnectedOnly(true))
+	if len(graph.Nodes) != 2 || graph.Nodes[0].ID != "User" || graph.Nodes[1].ID != "Pet" {
+		t.Errorf("Expected isolated Country to be dropped, got %+v", graph.Nodes)
+	}
+	if len(BuildGraph(g).Nodes) != 3 {
+		t.Error("Expected isolated entities to be kept by default")
+	}
+}
+
+func TestGeneratePDF(t *testing.T) {
+	b, err := GeneratePDF(newTestGraph(t))
+	if err != nil {
+		t.Fatalf("Failed to generate PDF: %v", err)
+	}
+	pdf := string(b)
+	if !strings.HasPrefix(pdf, "%PDF-1.4\n") || !strings.HasSuffix(pdf, "%%EOF\n") {
+		t.Fatal("Expected a complete PDF document")
+	}
+	for _, expected := range []string{"(ent schema - example.com/ent)", "(User)", "(age: int)", "(pets \\(1:N\\))", "(Legend)"} {
+		if !strings.Contains(pdf, expected) {
+			t.Errorf("Expected PDF to contain %s", expected)
+		}
+	}
+	// startxref 指向交叉引用表的起始位置。
+	var xref int
+	if _, err := fmt.Sscanf(pdf[strings.LastIndex(pdf, "startxref\n"):], "startxref\n%d", &xref); err != nil || !strings.HasPrefix(pdf[xref:], "xref\n") {
+		t.Errorf("Expected startxref to point to the xref table, got %d (%v)", xref, err)
+	}
+}
+
+func TestBuildGraphRowCounts(t *testing.T) {
+	graph := BuildGraph(newTestGraph(t), WithRowCounts(map[string]int64{"User": 1200000}))
+	if graph.Nodes[0].RowCount != 1200000 || graph.Nodes[1].RowCount != 0 {
+		t.Errorf("Expected row count only on User, got %+v", graph.Nodes)
+	}
+}
+
+func TestBuildGraphLabelTranslations(t *testing.T) {
+	graph := BuildGraph(newTestGraph(t), WithLabelTranslations(map[string]string{"User": "用户", "pets": "宠物"}), WithInlineCardinality(true))
+	if graph.Nodes[0].ID != "用户" || graph.Nodes[1].ID != "Pet" {
+		t.Errorf("Expected translated entity names with fallback, got %+v", graph.Nodes)
+	}
+	want := Edge{From: "用户", To: "Pet", Label: "宠物 (1:N)", Accessor: "QueryPets", Cardinality: "1:N", Bidirectional: true}
+	if len(graph.Edges) != 1 || graph.Edges[0] != want {
+		t.Errorf("Expected %+v, got %+v", want, graph.Edges)
+	}
+}
+
+func TestBuildGraphTransform(t *testing.T) {
+	dropPets := func(g Graph) Graph {
+		g.Edges = nil
+		return g
+	}
+	addNote := func(g Graph) Graph {
+		g.Nodes = append(g.Nodes, Node{ID: "Note", Kind: "note"})
+		return g
+	}
+	graph := BuildGraph(newTestGraph(t), WithGraphTransform(dropPets), WithGraphTransform(addNote))
+	if len(graph.Edges) != 0 || len(graph.Nodes) != 3 || graph.Nodes[2].ID != "Note" {
+		t.Errorf("Expected both transforms to be applied in order, got %+v", graph)
+	}
+	b, err := generateHTML(newTestGraph(t), newOptions(WithGraphTransform(addNote)))
+	if err != nil {
+		t.Fatalf("Failed to generate HTML: %v", err)
+	}
+	if !strings.Contains(string(b), `"id":"Note"`) {
+		t.Error("Expected the transformed graph to be embedded in the page")
+	}
+}
+
+func TestGenerateManifest(t *testing.T) {
+	g := newTestGraph(t, &load.Schema{Name: "Tag"})
+	b, err := generateManifest(g)
+	if err != nil {
+		t.Fatalf("Failed to generate manifest: %v", err)
+	}
+	var m struct {
+		Adjacency map[string][]neighbor `json:"adjacency"`
+	}
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatalf("Failed to decode manifest: %v", err)
+	}
+	want := map[string][]neighbor{
+		"User": {{Entity: "Pet", Label: "pets", Direction: "out"}},
+		"Pet":  {{Entity: "User", Label: "pets", Direction: "in"}},
+		"Tag":  {},
+	}
+	if !reflect.DeepEqual(m.Adjacency, want) {
+		t.Errorf("Expected adjacency %v, got %v", want, m.Adjacency)
+	}
+	graph, err := GraphFromJSON(b)
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+	if !reflect.DeepEqual(graph, BuildGraph(g)) {
+		t.Error("Expected the manifest graph to match BuildGraph")
+	}
+}
+
+func TestBuildGraphHighlightPattern(t *testing.T) {
+	g := newTestGraph(t, &load.Schema{Name: "LoginEvent"}, &load.Schema{Name: "EventLog"})
+	var highlighted []string
+	for _, n := range BuildGraph(g, WithHighlightPattern(`.*Event$`)).Nodes {
+		if n.Highlighted {
+			highlighted = append(highlighted, n.ID)
+		}
+	}
+	if !reflect.DeepEqual(highlighted, []string{"LoginEvent"}) {
+		t.Errorf("Expected only LoginEvent to be highlighted, got %v", highlighted)
+	}
+	for _, n := range BuildGraph(g, WithHighlightPattern(`(`)).Nodes {
+		if n.Highlighted {
+			t.Errorf("Expected no highlight for an invalid pattern, got %s", n.ID)
+		}
+	}
+	if _, err := generateHTML(g, newOptions(WithHighlightPattern(`(`))); err == nil || !strings.Contains(err.Error(), "invalid highlight pattern") {
+		t.Errorf("Expected an invalid pattern error, got %v", err)
+	}
+	noop := gen.GenerateFunc(func(*gen.Graph) error { return nil })
+	if err := visualizeSchema(newOptions(WithHighlightPattern(`(`)))(noop).Generate(g); err == nil {
+		t.Error("Expected the hook to fail for an invalid pattern")
+	}
+}
+
+func TestGenerateMarkdown(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "docs")
+	if err := GenerateMarkdown(newTestGraph(t), dir); err != nil {
+		t.Fatalf("Failed to generate Markdown: %v", err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "User.md"))
+	if err != nil {
+		t.Fatalf("Failed to read User.md: %v", err)
+	}
+	doc := string(b)
+	for _, expected := range []string{
+		"# User\n",
+		"| name | string |  |  |\n",
+		"| age | int | 用户年龄 | optional |\n",
+		"## Relationships\n\n- pets → Pet (1:N)\n",
+	} {
+		if !strings.Contains(doc, expected) {
+			t.Errorf("Expected User.md to contain %q, got:\n%s", expected, doc)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Pet.md")); err != nil {
+		t.Errorf("Expected Pet.md: %v", err)
+	}
+}
+
+func TestBuildGraphImmutableEdges(t *testing.T) {
+	g := newTestGraph(t, &load.Schema{
+		Name: "Photo",
+		Edges: []*load.Edge{
+			{Name: "uploader", Type: "User", Unique: true, Immutable: true},
+		},
+	})
+	for _, e := range BuildGraph(g).Edges {
+		if e.Immutable != (e.Label == "uploader") {
+			t.Errorf("Unexpected immutable flag %v for %s", e.Immutable, e.Label)
+		}
+	}
+	// 声明在反向边上的不可变同样适用于正向边。
+	g.Nodes[1].Edges[0].Immutable = true
+	if e := BuildGraph(g).Edges[0]; e.Label != "pets" || !e.Immutable {
+		t.Errorf("Expected pets to be immutable through its inverse edge, got %+v", e)
+	}
+}
+
+func TestVisualizeSchemaDryRun(t *testing.T) {
+	g := newTestGraph(t)
+	g.Config.Target = t.TempDir()
+	noop := gen.GenerateFunc(func(*gen.Graph) error { return nil })
+	if err := visualizeSchema(newOptions(WithDryRun(true)))(noop).Generate(g); err != nil {
+		t.Fatalf("Failed to run hook: %v", err)
+	}
+	if entries, _ := os.ReadDir(g.Config.Target); len(entries) != 0 {
+		t.Errorf("Expected no files to be written, got %v", entries)
+	}
+	if templates := NewExtension(WithDryRun(true)).Templates(); len(templates) != 0 {
+		t.Errorf("Expected no templates in dry-run mode, got %d", len(templates))
+	}
+}
+
+func TestGenerateHTMLFieldGrouping(t *testing.T) {
+	b, err := generateHTML(newTestGraph(t), newOptions(WithFieldGrouping("required-first")))
+	if err != nil {
+		t.Fatalf("Failed to generate HTML: %v", err)
+	}
+	if expected := regexp.MustCompile(`const fieldGrouping = \s*"required-first"\s*;`); !expected.Match(b) {
+		t.Errorf("Expected page to match %q", expected)
+	}
+}
+
+func TestGenerateGraphML(t *testing.T) {
+	b, err := GenerateGraphML(newTestGraph(t))
+	if err != nil {
+		t.Fatalf("Failed to generate GraphML: %v", err)
+	}
+	var doc graphML
+	if err := xml.Unmarshal(b, &doc); err != nil {
+		t.Fatalf("Failed to parse GraphML: %v", err)
+	}
+	if len(doc.Graph.Nodes) != 2 || doc.Graph.Nodes[0].ID != "User" {
+		t.Fatalf("Expected User and Pet nodes, got %+v", doc.Graph.Nodes)
+	}
+	if fields := doc.Graph.Nodes[0].Data[1]; fields.Key != "fields" || fields.Value != "name: string\nage: int" {
+		t.Errorf("Expected User fields, got %+v", fields)
+	}
+	edge := doc.Graph.Edges[0]
+	if edge.Source != "User" || edge.Target != "Pet" || edge.Data[0].Value != "pets" || edge.Data[1].Value != "1:N" {
+		t.Errorf("Expected pets edge, got %+v", edge)
+	}
+}
+
+func TestBuildGraphPolicyAndHooks(t *testing.T) {
+	g := newTestGraph(t,
+		&load.Schema{Name: "Secret", Policy: []*load.Position{{Index: 0}}},
+		&load.Schema{Name: "Audit", Hooks: []*load.Position{{Index: 0}, {Index: 1}}},
+	)
+	for _, n := range BuildGraph(g).Nodes {
+		if n.HasPolicy != (n.ID == "Secret") || n.HasHooks != (n.ID == "Audit") {
+			t.Errorf("Unexpected policy/hooks flags for %s: %+v", n.ID, n)
+		}
+	}
+}
+
+func TestGenerateHTMLEdgeLabelStyle(t *testing.T) {
+	g := newTestGraph(t)
+	for _, tt := range []struct {
+		opts     []Option
+		expected string
+	}{
+		{nil, `const edgeFont = \{ size: \s*12\s*, background: \s*"white"\s*, \.\.\.\(\s*false\s* \?`},
+		{[]Option{WithEdgeLabelStyle(16, "#ffffcc", true)}, `const edgeFont = \{ size: \s*16\s*, background: \s*"#ffffcc"\s*, \.\.\.\(\s*true\s* \?`},
+	} {
+		b, err := generateHTML(g, newOptions(tt.opts...))
+		if err != nil {
+			t.Fatalf("Failed to generate HTML: %v", err)
+		}
+		if expected := regexp.MustCompile(tt.expected); !expected.Match(b) {
+			t.Errorf("Expected page to match %q", expected)
+		}
+	}
+}
+
+func TestMergeGraphs(t *testing.T) {
+	users := newTestGraph(t)
+	users.Config.Package = "github.com/acme/users/ent"
+	billing, err := gen.NewGraph(&gen.Config{Package: "github.com/acme/billing/ent", Storage: &gen.Storage{Name: "sql"}}, &load.Schema{
+		Name:        "Invoice",
+		Annotations: map[string]any{"EntViz": CrossServiceLink("user_id", "users.User")},
+	})
+	if err != nil {
+		t.Fatalf("Failed to build graph: %v", err)
+	}
+	merged, err := MergeGraphs(users, billing)
+	if err != nil {
+		t.Fatalf("Failed to merge graphs: %v", err)
+	}
+	var ids []string
+	for _, n := range merged.Nodes {
+		ids = append(ids, n.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"users.User", "users.Pet", "billing.Invoice"}) {
+		t.Errorf("Expected namespaced entities, got %v", ids)
+	}
+	var edges []string
+	for _, e := range merged.Edges {
+		edges = append(edges, e.From+" -"+e.Label+"-> "+e.To)
+	}
+	if !reflect.DeepEqual(edges, []string{"users.User -pets-> users.Pet", "billing.Invoice -user_id-> users.User"}) {
+		t.Errorf("Expected intra-service and cross-service edges, got %v", edges)
+	}
+	if user := merged.Nodes[0]; user.InDegree != 1 || user.OutDegree != 1 {
+		t.Errorf("Expected degrees to include the cross-service link, got %+v", user)
+	}
+	billing.Nodes[0].Annotations["EntViz"] = CrossServiceLink("account_id", "accounts.Account")
+	if _, err := MergeGraphs(users, billing); err == nil || !strings.Contains(err.Error(), `unknown entity "accounts.Account"`) {
+		t.Errorf("Expected an unknown link target error, got %v", err)
+	}
+}
+
+func TestGraphJSONSchema(t *testing.T) {
+	b, err := Asset("graph.schema.json")
+	if err != nil {
+		t.Fatalf("Failed to read JSON Schema: %v", err)
+	}
+	type object struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	var schema struct {
+		object
+		Definitions map[string]object `json:"definitions"`
+	}
+	if err := json.Unmarshal(b, &schema); err != nil {
+		t.Fatalf("Failed to parse JSON Schema: %v", err)
+	}
+	// 每个结构体的每个 JSON 键都必须在 JSON Schema 中有定义。
+	for _, tt := range []struct {
+		typ        reflect.Type
+		properties map[string]json.RawMessage
+	}{
+		{reflect.TypeOf(Graph{}), schema.Properties},
+		{reflect.TypeOf(Node{}), schema.Definitions["node"].Properties},
+		{reflect.TypeOf(Edge{}), schema.Definitions["edge"].Properties},
+		{reflect.TypeOf(Field{}), schema.Definitions["field"].Properties},
+	} {
+		for i := 0; i < tt.typ.NumField(); i++ {
+			key, _, _ := strings.Cut(tt.typ.Field(i).Tag.Get("json"), ",")
+			if _, ok := tt.properties[key]; !ok {
+				t.Errorf("Expected JSON Schema to describe %s.%s", tt.typ.Name(), key)
+			}
+		}
+	}
+}
+
+func TestBuildGraphMuteFields(t *testing.T) {
+	graph := BuildGraph(newTestGraph(t), WithMuteFields([]string{"age", "created_at"}))
+	for _, f := range graph.Nodes[0].Fields {
+		if f.Muted != (f.Name == "age") {
+			t.Errorf("Unexpected muted flag %v for %s", f.Muted, f.Name)
+		}
+	}
+}
+
+func TestGenerateHTMLNoVendor(t *testing.T) {
+	b, err := GenerateHTMLNoVendor(newTestGraph(t))
+	if err != nil {
+		t.Fatalf("Failed to generate HTML fragment: %v", err)
+	}
+	fragment := string(b)
+	for _, tag := range []string{"<html", "<head>", "<body>"} {
+		if strings.Contains(fragment, tag) {
+			t.Errorf("Expected no %s in fragment", tag)
+		}
+	}
+	// 样式和脚本都在容器内，片段只有一个顶层元素，也不包含 fira_code.css。
+	if !strings.HasPrefix(strings.TrimSpace(fragment), `<div class="entviz">`) || !strings.HasSuffix(strings.TrimSpace(fragment), "</div>") {
+		t.Error("Expected fragment to be wrapped in the container")
+	}
+	if firaCodeCSS, _ := Asset("fira_code.css"); strings.Contains(fragment, strings.TrimSpace(string(firaCodeCSS))) {
+		t.Error("Expected fragment without the font CSS")
+	}
+	// 片段中的每条样式规则都只作用于 .entviz 容器内的元素，不影响宿主页面。
+	for _, style := range regexp.MustCompile(`(?s)<style[^>]*>(.*?)</style>`).FindAllStringSubmatch(fragment, -1) {
+		css := regexp.MustCompile(`(?s)/\*.*?\*/`).ReplaceAllString(style[1], "")
+		for _, rule := range regexp.MustCompile(`([^{}]+)\{`).FindAllStringSubmatch(css, -1) {
+			for _, selector := range strings.Split(rule[1], ",") {
+				if selector = strings.TrimSpace(selector); !strings.HasPrefix(selector, ".entviz") {
+					t.Errorf("Expected selector %q to be scoped to .entviz", selector)
+				}
+			}
+		}
+	}
+	for _, want := range []string{`<div class="entviz">`, `"id":"User"`, "new vis.Network("} {
+		if !strings.Contains(fragment, want) {
+			t.Errorf("Expected fragment to contain %q", want)
+		}
+	}
+	visNetworkJS, err := Asset("vis-network.min.js")
+	if err != nil {
+		t.Fatalf("Failed to read asset: %v", err)
+	}
+	if len(b) >= len(visNetworkJS) {
+		t.Errorf("Expected fragment (%d bytes) to be smaller than the vis-network library (%d bytes)", len(b), len(visNetworkJS))
+	}
+}
+
+func TestBuildGraphStableIDs(t *testing.T) {
+	g := newTestGraph(t, &load.Schema{
+		Name:  "Tag",
+		Pos:   "/src/ent/schema/tag.go:12",
+		Edges: []*load.Edge{{Name: "creator", Type: "User", Unique: true}},
+	})
+	graph := buildGraph(g, newOptions(WithStableIDs(true)))
+	labels := make(map[string]string)
+	for _, n := range graph.Nodes {
+		labels[n.ID] = n.Label
+	}
+	if label, ok := labels["tag.go#Tag"]; !ok || label != "Tag" {
+		t.Errorf("Expected Tag to have stable ID tag.go#Tag and label Tag, got %v", labels)
+	}
+	// 没有位置信息的类型继续使用名称作为 ID。
+	if label, ok := labels["User"]; !ok || label != "" {
+		t.Errorf("Expected User to keep its name as ID without a label, got %v", labels)
+	}
+	var found bool
+	for _, e := range graph.Edges {
+		if e.Label == "creator" {
+			found = e.From == "tag.go#Tag" && e.To == "User"
+		}
+	}
+	if !found {
+		t.Errorf("Expected creator edge to use the stable ID, got %+v", graph.Edges)
+	}
+	graph = buildGraph(g, newOptions(WithStableIDs(true), WithSavedPositions(map[string][2]float64{"tag.go#Tag": {1, 2}})))
+	for _, n := range graph.Nodes {
+		if n.ID == "tag.go#Tag" && (n.X == nil || *n.X != 1 || *n.Y != 2) {
+			t.Errorf("Expected saved position keyed by the stable ID, got %+v", n)
+		}
+	}
+	for _, n := range buildGraph(g, newOptions()).Nodes {
+		if n.Label != "" || n.ID == "tag.go#Tag" {
+			t.Errorf("Expected names as IDs by default, got %+v", n)
+		}
+	}
+	// ?focus=Tag 找不到 ID 为 Tag 的节点时，页面按 label 查找实体。
+	b, err := generateHTML(g, newOptions(WithStableIDs(true)))
+	if err != nil {
+		t.Fatalf("Failed to generate HTML: %v", err)
+	}
+	for _, want := range []string{`"id":"tag.go#Tag"`, `"label":"Tag"`, `(entGraph.nodes || []).find(n => n.label === focusName)`} {
+		if !bytes.Contains(b, []byte(want)) {
+			t.Errorf("Expected page to contain %q", want)
+		}
+	}
+}
+
+func TestGenerateHTMLSummaryTable(t *testing.T) {
+	g := newTestGraph(t)
+	b, err := generateHTML(g, newOptions(WithSummaryTable(true)))
+	if err != nil {
+		t.Fatalf("Failed to generate HTML: %v", err)
+	}
+	page := string(b)
+	for _, row := range []string{
+		`<tr><td>User</td><td class="count">2</td><td class="count">0</td><td class="count">1</td></tr>`,
+		`<tr><td>Pet</td><td class="count">1</td><td class="count">1</td><td class="count">0</td></tr>`,
+	} {
+		if !strings.Contains(page, row) {
+			t.Errorf("Expected summary row %s", row)
+		}
+	}
+	b, err = generateHTML(g, newOptions())
+	if err != nil {
+		t.Fatalf("Failed to generate HTML: %v", err)
+	}
+	if strings.Contains(string(b), `<table id="summary"`) {
+		t.Error("Expected no summary table by default")
+	}
+}
+
+func TestBuildGraphEdgeConstraints(t *testing.T) {
+	g := newTestGraph(t, &load.Schema{
+		Name:   "Card",
+		Fields: []*load.Field{{Name: "number", Info: &field.TypeInfo{Type: field.TypeString}}},
+		Edges:  []*load.Edge{{Name: "owner", Type: "User", Unique: true}},
+		Indexes: []*load.Index{
+			{Unique: true, Fields: []string{"number"}, Edges: []string{"owner"}},
+			{Unique: true, Fields: []string{"number"}},
+		},
+	}, &load.Schema{
+		Name:  "Team",
+		Edges: []*load.Edge{{Name: "members", Type: "Member"}},
+	}, &load.Schema{
+		Name:    "Member",
+		Fields:  []*load.Field{{Name: "email", Info: &field.TypeInfo{Type: field.TypeString}}},
+		Edges:   []*load.Edge{{Name: "team", Type: "Team", RefName: "members", Unique: true, Inverse: true}},
+		Indexes: []*load.Index{{Unique: true, Fields: []string{"email"}, Edges: []string{"team"}}},
+	})
+	graph := buildGraph(g, newOptions(WithEdgeConstraints(true)))
+	for _, n := range graph.Nodes {
+		want := []string(nil)
+		switch n.ID {
+		case "Card":
+			want = []string{"unique(number, owner)"}
+		case "Member":
+			want = []string{"unique(email, team)"}
+		}
+		if !reflect.DeepEqual(n.Constraints, want) {
+			t.Errorf("Expected constraints %v on %s, got %v", want, n.ID, n.Constraints)
+		}
+	}
+	for _, e := range graph.Edges {
+		// 反向边 team 的约束记录在正向边 members 上。
+		want := map[string]string{"owner": "unique(number, owner)", "members": "unique(email, team)"}[e.Label]
+		if e.Constraint != want {
+			t.Errorf("Expected constraint %q on %s, got %q", want, e.Label, e.Constraint)
+		}
+	}
+	for _, n := range buildGraph(g, newOptions()).Nodes {
+		if n.Constraints != nil {
+			t.Errorf("Expected no constraints by default, got %+v", n)
+		}
+	}
+}
+
+func TestGenerateHTMLDirection(t *testing.T) {
+	g := newTestGraph(t)
+	for _, tt := range []struct {
+		direction string
+		expected  string
+	}{
+		{"", `""`},
+		{"LR", `"LR"`},
+		{"RL", `"RL"`},
+		{"TB", `"UD"`},
+		{"BT", `"DU"`},
+		{"diagonal", `""`},
+	} {
+		b, err := generateHTML(g, newOptions(WithDirection(tt.direction)))
+		if err != nil {
+			t.Fatalf("Failed to generate HTML: %v", err)
+		}
+		if expected := regexp.MustCompile(`const direction = \s*` + tt.expected + `\s*;`); !expected.Match(b) {
+			t.Errorf("Expected direction %q to match %q", tt.direction, expected)
+		}
+	}
+}
+
+func TestComplexityMetrics(t *testing.T) {
+	g := newTestGraph(t,
+		&load.Schema{Name: "Tag"},
+		&load.Schema{Name: "Toy", Edges: []*load.Edge{
+			{Name: "pet", Type: "Pet", Unique: true},
+			{Name: "maker", Type: "User", Unique: true},
+		}},
+	)
+	expected := Metrics{
+		Entities:      4,
+		Relationships: 3,
+		EdgeNodeRatio: 0.75,
+		Components:    2,
+		// User、Pet 和 Toy 之间有一个环路。
+		Cyclomatic: 3,
+		// Toy 依赖 Pet，Pet 依赖 User。
+		MaxDepth:  2,
+		FanIn:     map[int]int{0: 2, 1: 1, 2: 1},
+		FanOut:    map[int]int{0: 2, 1: 1, 2: 1},
+		MaxFanIn:  2,
+		MaxFanOut: 2,
+	}
+	if metrics := ComplexityMetrics(g); !reflect.DeepEqual(metrics, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, metrics)
+	}
+}
+
+func TestBuildGraphSQLConstraints(t *testing.T) {
+	size := int64(32)
+	g := newTestGraph(t, &load.Schema{Name: "Account", Fields: []*load.Field{
+		{Name: "status", Info: &field.TypeInfo{Type: field.TypeString}, Size: &size, Default: true, DefaultValue: "it's active", DefaultKind: reflect.String},
+		{Name: "email", Info: &field.TypeInfo{Type: field.TypeString}, Unique: true},
+		{Name: "score", Info: &field.TypeInfo{Type: field.TypeInt}, Optional: true, Default: true, DefaultValue: 10, DefaultKind: reflect.Int},
+	}})
+	for _, tt := range []struct {
+		dialect  string
+		expected []string
+	}{
+		{"mysql", []string{"varchar(32) NOT NULL DEFAULT 'it''s active'", "varchar(255) NOT NULL UNIQUE", "bigint DEFAULT 10"}},
+		{"postgres", []string{"character varying(32) NOT NULL DEFAULT 'it''s active'", "character varying NOT NULL UNIQUE", "bigint DEFAULT 10"}},
+	} {
+		graph := BuildGraph(g, WithSQLConstraints(true), WithDialect(tt.dialect))
+		var got []string
+		for _, f := range graph.Nodes[2].Fields {
+			got = append(got, f.SQL)
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("Expected %s column definitions %q, got %q", tt.dialect, tt.expected, got)
+		}
+	}
+	for _, f := range BuildGraph(g).Nodes[2].Fields {
+		if f.SQL != "" {
+			t.Errorf("Expected no column definition by default, got %q", f.SQL)
+		}
+	}
+}
+
+func TestGenerateComponentPages(t *testing.T) {
+	g := newTestGraph(t,
+		&load.Schema{Name: "Tag", Edges: []*load.Edge{{Name: "labels", Type: "Label"}, {Name: "colors", Type: "Color"}}},
+		&load.Schema{Name: "Label"},
+		&load.Schema{Name: "Color"},
+	)
+	dir := filepath.Join(t.TempDir(), "components")
+	if err := GenerateComponentPages(g, dir); err != nil {
+		t.Fatalf("Failed to generate component pages: %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read output directory: %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	// User 和 Pet 的关系数量相同，取名称靠前的 Pet；Tag 是第二个分量中关系最多的实体。
+	if expected := []string{"Pet.html", "Tag.html"}; !reflect.DeepEqual(names, expected) {
+		t.Fatalf("Expected pages %v, got %v", expected, names)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "Tag.html"))
+	if err != nil {
+		t.Fatalf("Failed to read Tag.html: %v", err)
+	}
+	page := string(b)
+	for _, id := range []string{"Tag", "Label", "Color"} {
+		if !strings.Contains(page, `"id":"`+id+`"`) {
+			t.Errorf("Expected %s in the Tag page", id)
+		}
+	}
+	if strings.Contains(page, `"id":"User"`) {
+		t.Error("Expected User only in its own component page")
+	}
+}
+
+func TestBuildGraphOnDelete(t *testing.T) {
+	g := newTestGraph(t, &load.Schema{
+		Name: "Post",
+		Edges: []*load.Edge{
+			{Name: "comments", Type: "Comment", Annotations: map[string]any{"EntSQL": entsql.OnDelete(entsql.Cascade)}},
+			{Name: "tags", Type: "Tag"},
+		},
+	}, &load.Schema{
+		Name:  "Comment",
+		Edges: []*load.Edge{{Name: "post", Type: "Post", RefName: "comments", Unique: true, Inverse: true}},
+	}, &load.Schema{
+		Name: "Tag",
+		// 注解写在反向边上时同样生效。
+		Edges: []*load.Edge{{Name: "posts", Type: "Post", RefName: "tags", Inverse: true, Annotations: map[string]any{"EntSQL": entsql.OnDelete(entsql.Restrict)}}},
+	})
+	onDelete := make(map[string]string)
+	for _, e := range BuildGraph(g).Edges {
+		onDelete[e.Label] = e.OnDelete
+	}
+	expected := map[string]string{"pets": "", "comments": "CASCADE", "tags": "RESTRICT"}
+	if !reflect.DeepEqual(onDelete, expected) {
+		t.Errorf("Expected %v, got %v", expected, onDelete)
+	}
+}
+
+func TestExportGraphJSONOmitEmpty(t *testing.T) {
+	g := newTestGraph(t)
+	verbose, err := ExportGraphJSON(g)
+	if err != nil {
+		t.Fatalf("Failed to export graph JSON: %v", err)
+	}
+	compact, err := ExportGraphJSON(g, WithOmitEmpty(true))
+	if err != nil {
+		t.Fatalf("Failed to export graph JSON: %v", err)
+	}
+	for _, omitted := range []string{`"comment":""`, `"required":false`} {
+		if !strings.Contains(string(verbose), omitted) {
+			t.Errorf("Expected %s in the default output", omitted)
+		}
+		if strings.Contains(string(compact), omitted) {
+			t.Errorf("Expected no %s in the compact output", omitted)
+		}
+	}
+	if !strings.Contains(string(compact), `"comment":"用户年龄"`) || !strings.Contains(string(compact), `"inDegree":0`) {
+		t.Errorf("Expected non-empty values and numbers to be kept, got %s", compact)
+	}
+	if len(compact) >= len(verbose) {
+		t.Errorf("Expected compact output (%d bytes) to be smaller than the default (%d bytes)", len(compact), len(verbose))
+	}
+	graph, err := GraphFromJSON(compact)
+	if err != nil {
+		t.Fatalf("Failed to read compact graph JSON: %v", err)
+	}
+	if expected := BuildGraph(g); !reflect.DeepEqual(graph, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, graph)
+	}
+}
+
+func TestBuildGraphEntityDescriptions(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "schema.go")
+	src := `package schema
+
+// Account 是系统中的付费账户。
+// 每个账户可以有多个用户。
+type Account struct{ ent.Schema }
+
+// Tag holds the schema definition for the Tag entity.
+type Tag struct{ ent.Schema }
+
+type (
+	// Invoice 是账单。
+	Invoice struct{ ent.Schema }
+)
+`
+	if err := os.WriteFile(file, []byte(src), 0644); err != nil {
+		t.Fatalf("Failed to write schema file: %v", err)
+	}
+	g := newTestGraph(t,
+		&load.Schema{Name: "Account", Pos: file + ":5"},
+		&load.Schema{Name: "Tag", Pos: file + ":8"},
+		&load.Schema{Name: "Invoice", Pos: file + ":12"},
+		&load.Schema{Name: "Plan", Annotations: map[string]any{"Comment": schema.Comment("订阅套餐")}},
+	)
+	descriptions := make(map[string]string)
+	for _, n := range BuildGraph(g, WithEntityDescriptions(true)).Nodes {
+		descriptions[n.ID] = n.Description
+	}
+	expected := map[string]string{
+		"User":    "",
+		"Pet":     "",
+		"Account": "Account 是系统中的付费账户。\n每个账户可以有多个用户。",
+		"Tag":     "",
+		"Invoice": "Invoice 是账单。",
+		"Plan":    "订阅套餐",
+	}
+	if !reflect.DeepEqual(descriptions, expected) {
+		t.Errorf("Expected %q, got %q", expected, descriptions)
+	}
+	for _, n := range BuildGraph(g).Nodes {
+		if n.Description != "" {
+			t.Errorf("Expected no description by default, got %+v", n)
+		}
+	}
+	// 同一个源文件只解析一次，之后的实体从缓存中查找文档注释。
+	docs := map[string]map[string]string{file: {"Account": "cached"}}
+	if got := entityDescription(g.Nodes[2], docs); got != "cached" {
+		t.Errorf("Expected the cached doc comment, got %q", got)
+	}
+}
+
+// serveEntvizTest 是在生成的代码中运行的 ServeEntviz 处理器测试。
+const serveEntvizTest = `package ent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServeEntviz(t *testing.T) {
+	for _, tt := range []struct{ method, path, accept, contentType, body string }{
+		{"GET", "/", "", "text/html", "<html"},
+		{"GET", "/", "text/html;q=0, application/json", "application/json", "{"},
+		{"GET", "/", "application/json;q=0.1, text/vnd.graphviz", "text/vnd.graphviz", "digraph"},
+		{"GET", "/", "application/json, text/html", "application/json", "{"},
+		{"GET", "/", "text/html;q=0, */*;q=0.5", "application/json", "{"},
+		{"GET", "/healthz", "", "text/plain", "ok"},
+		{"HEAD", "/healthz", "", "text/plain", ""},
+		{"GET", "/docs/healthz", "", "text/html", "<html"},
+	} {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+		rec := httptest.NewRecorder()
+		ServeEntviz().ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), tt.contentType) || !strings.HasPrefix(rec.Body.String(), tt.body) {
+			t.Errorf("%s %s (Accept %q): got %d %s", tt.method, tt.path, tt.accept, rec.Code, rec.Header().Get("Content-Type"))
+		}
+	}
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "text/html;q=0")
+	rec := httptest.NewRecorder()
+	ServeEntviz().ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotAcceptable {
+		t.Errorf("Expected 406 when every format is excluded, got %d", rec.Code)
+	}
+}
+`
+
+func TestServeEntvizHandler(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles the generated code")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not available")
+	}
+	g := newTestGraph(t)
+	dir := t.TempDir()
+	g.Config.Target = dir
+	files, err := generateFiles(g, newOptions())
+	if err != nil {
+		t.Fatalf("Failed to generate files: %v", err)
+	}
+	var code bytes.Buffer
+	tmpl := gen.MustParse(gen.NewTemplate("entviz").Parse(`{{ define "header" }}package ent{{ end }}` + tmplfile))
+	if err := tmpl.ExecuteTemplate(&code, "entviz", g); err != nil {
+		t.Fatalf("Failed to execute template: %v", err)
+	}
+	files["entviz.go"] = code.Bytes()
+	files["entviz_test.go"] = []byte(serveEntvizTest)
+	files["go.mod"] = []byte("module example.com/ent\n\ngo 1.24\n")
+	for name, buf := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), buf, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := exec.Command(goBin, "test", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("Generated handler tests failed: %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,83 @@
+package entviz
+
+import (
+	"slices"
+	"strings"
+
+	"entgo.io/ent/entc/gen"
+)
+
+// enumNodes 为 schema 中的枚举字段生成节点，以及从使用它的实体指向该节点的 "uses" 边，边的标签为字段名称。
+// 通过 GoType 指定了同一个 Go 类型且取值相同的枚举字段视为共享的枚举，只生成一个以类型名称命名的节点，
+// 例如 "Status enum"，从而展示多个实体共享的枚举；类型名称相同但类型或取值不同时改用 "User.Status enum" 区分。
+// 其他枚举字段属于各自的实体，节点 ID 包含实体名称，例如 "User.status enum"，Label 为 "status enum"。
+// 节点的字段为枚举的各个取值。
+func enumNodes(g *gen.Graph) ([]Node, []Edge) {
+	type enum struct {
+		name   string
+		shared bool
+		values []string
+		users  []*gen.Field
+		owners []string
+	}
+	var (
+		enums []*enum
+		byKey = make(map[string]*enum)
+	)
+	for _, n := range g.Nodes {
+		for _, f := range n.Fields {
+			if !f.IsEnum() {
+				continue
+			}
+			values := f.EnumValues()
+			key := n.Name + "." + f.Name
+			if f.HasGoType() {
+				key = f.Type.RType.PkgPath + "." + f.Type.String() + "\x00" + strings.Join(slices.Sorted(slices.Values(values)), "\x00")
+			}
+			e, ok := byKey[key]
+			if !ok {
+				e = &enum{name: enumName(f), shared: f.HasGoType(), values: values}
+				byKey[key] = e
+				enums = append(enums, e)
+			}
+			e.users = append(e.users, f)
+			e.owners = append(e.owners, n.Name)
+		}
+	}
+	names := make(map[string]int)
+	for _, e := range enums {
+		if e.shared {
+			names[e.name]++
+		}
+	}
+	var (
+		nodes []Node
+		edges []Edge
+	)
+	for _, e := range enums {
+		node := Node{ID: e.name + " enum", Kind: "enum"}
+		switch {
+		case !e.shared:
+			node.ID, node.Label = e.owners[0]+"."+e.name+" enum", e.name+" enum"
+		case names[e.name] > 1:
+			node.ID = e.owners[0] + "." + e.name + " enum"
+		}
+		for _, v := range e.values {
+			node.Fields = append(node.Fields, Field{Name: v, Category: "enum"})
+		}
+		nodes = append(nodes, node)
+		for i, f := range e.users {
+			edges = append(edges, Edge{From: e.owners[i], To: node.ID, Label: f.Name, Kind: "uses"})
+		}
+	}
+	return nodes, edges
+}
+
+// enumName 返回枚举的名称：通过 GoType 指定了类型时使用去掉包名的类型名称，否则使用字段名称。
+func enumName(f *gen.Field) string {
+	if f.HasGoType() {
+		name := f.Type.String()
+		return name[strings.LastIndex(name, ".")+1:]
+	}
+	return f.Name
+}
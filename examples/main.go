@@ -1,11 +1,11 @@
 package main
 
 import (
-	"net/http"
+	"log"
 
 	"github.com/taerc/entviz/examples/ent"
 )
 
 func main() {
-	http.ListenAndServe("localhost:3002", ent.ServeEntviz())
+	log.Fatal(ent.ListenAndServeEntviz("localhost:3002"))
 }
@@ -0,0 +1,204 @@
+package entviz
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"entgo.io/ent/entc/gen"
+)
+
+const (
+	// excalidrawFontSize 是 Excalidraw 文本的字号。
+	excalidrawFontSize = 16
+	// excalidrawLineHeight 是每行文本的高度。
+	excalidrawLineHeight = 20
+	// excalidrawCharWidth 是等宽字体单个字符的近似宽度。
+	excalidrawCharWidth = 9.6
+	// excalidrawGap 是网格中相邻实体之间的间距。
+	excalidrawGap = 120
+)
+
+type (
+	// excalidrawFile 是 .excalidraw 文件的顶层结构。
+	excalidrawFile struct {
+		Type     string              `json:"type"`
+		Version  int                 `json:"version"`
+		Source   string              `json:"source"`
+		Elements []excalidrawElement `json:"elements"`
+		AppState map[string]any      `json:"appState"`
+		Files    map[string]any      `json:"files"`
+	}
+
+	// excalidrawElement 是 Excalidraw 中的单个图形元素，这里只用到矩形、文本和箭头，
+	// 其余属性在 Excalidraw 打开文件时会补全为默认值。
+	excalidrawElement struct {
+		ID              string              `json:"id"`
+		Type            string              `json:"type"`
+		X               float64             `json:"x"`
+		Y               float64             `json:"y"`
+		Width           float64             `json:"width"`
+		Height          float64             `json:"height"`
+		StrokeColor     string              `json:"strokeColor"`
+		BackgroundColor string              `json:"backgroundColor"`
+		FillStyle       string              `json:"fillStyle"`
+		StrokeWidth     int                 `json:"strokeWidth"`
+		Roughness       int                 `json:"roughness"`
+		Opacity         int                 `json:"opacity"`
+		Seed            int                 `json:"seed"`
+		BoundElements   []excalidrawBinding `json:"boundElements,omitempty"`
+		// 以下为文本元素的属性。
+		Text          string `json:"text,omitempty"`
+		OriginalText  string `json:"originalText,omitempty"`
+		FontSize      int    `json:"fontSize,omitempty"`
+		FontFamily    int    `json:"fontFamily,omitempty"`
+		TextAlign     string `json:"textAlign,omitempty"`
+		VerticalAlign string `json:"verticalAlign,omitempty"`
+		ContainerID   string `json:"containerId,omitempty"`
+		// 以下为箭头元素的属性。
+		Points       [][2]float64      `json:"points,omitempty"`
+		StartBinding *excalidrawAnchor `json:"startBinding,omitempty"`
+		EndBinding   *excalidrawAnchor `json:"endBinding,omitempty"`
+		EndArrowhead string            `json:"endArrowhead,omitempty"`
+	}
+
+	// excalidrawBinding 记录绑定到某个元素上的文本或箭头。
+	excalidrawBinding struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+	}
+
+	// excalidrawAnchor 记录箭头端点连接的元素。
+	excalidrawAnchor struct {
+		ElementID string  `json:"elementId"`
+		Focus     float64 `json:"focus"`
+		Gap       float64 `json:"gap"`
+	}
+)
+
+// GenerateExcalidraw 生成可以在 Excalidraw 中打开和继续编辑的 .excalidraw 文件。
+// 每个实体是一个包含名称和字段的矩形，按网格排列，关系以连接两个矩形的箭头表示。
+//
+// 参数：
+//   - g: 包含 schema 信息的 Ent 生成图
+//
+// 返回：
+//   - []byte: .excalidraw 文件内容
+//   - error: 如果序列化过程中发生错误则返回错误
+func GenerateExcalidraw(g *gen.Graph) ([]byte, error) {
+	graph := BuildGraph(g, WithTypeShortening(true))
+	file := excalidrawFile{
+		Type:     "excalidraw",
+		Version:  2,
+		Source:   "https://github.com/taerc/entviz",
+		Elements: []excalidrawElement{},
+		AppState: map[string]any{"viewBackgroundColor": "#ffffff"},
+		Files:    map[string]any{},
+	}
+
+	// 所有实体使用相同大小的网格单元，单元大小由最大的实体决定。
+	texts := make([]string, len(graph.Nodes))
+	var cellWidth, cellHeight float64
+	for i, n := range graph.Nodes {
+		lines := []string{n.ID}
+		for _, f := range n.Fields {
+			lines = append(lines, f.Name+": "+f.Type)
+		}
+		texts[i] = strings.Join(lines, "\n")
+		w, h := excalidrawTextSize(lines)
+		cellWidth, cellHeight = max(cellWidth, w), max(cellHeight, h)
+	}
+	columns := int(math.Ceil(math.Sqrt(float64(len(graph.Nodes)))))
+
+	// rects 记录每个实体对应的矩形在 Elements 中的位置。
+	rects := make(map[string]int, len(graph.Nodes))
+	var seed int
+	nextSeed := func() int {
+		seed++
+		return seed
+	}
+	for i, n := range graph.Nodes {
+		id := fmt.Sprintf("node-%d", i)
+		textID := id + "-text"
+		lines := strings.Split(texts[i], "\n")
+		w, h := excalidrawTextSize(lines)
+		x := float64(i%columns) * (cellWidth + excalidrawGap)
+		y := float64(i/columns) * (cellHeight + excalidrawGap)
+		rects[n.ID] = len(file.Elements)
+		file.Elements = append(file.Elements,
+			excalidrawElement{
+				ID: id, Type: "rectangle", X: x, Y: y, Width: w, Height: h,
+				StrokeColor: "#1e1e1e", BackgroundColor: n.Color, FillStyle: "solid",
+				StrokeWidth: 1, Roughness: 0, Opacity: 100, Seed: nextSeed(),
+				BoundElements: []excalidrawBinding{{ID: textID, Type: "text"}},
+			},
+			excalidrawElement{
+				ID: textID, Type: "text", X: x, Y: y, Width: w, Height: h,
+				StrokeColor: "#1e1e1e", BackgroundColor: "transparent", FillStyle: "solid",
+				StrokeWidth: 1, Roughness: 0, Opacity: 100, Seed: nextSeed(),
+				Text: texts[i], OriginalText: texts[i], FontSize: excalidrawFontSize, FontFamily: 3,
+				TextAlign: "left", VerticalAlign: "top", ContainerID: id,
+			},
+		)
+	}
+
+	var arrows []excalidrawElement
+	for i, e := range graph.Edges {
+		fi, ok1 := rects[e.From]
+		ti, ok2 := rects[e.To]
+		if !ok1 || !ok2 {
+			continue
+		}
+		// 矩形已全部添加，箭头单独收集，因此这里的指针不会失效。
+		from, to := &file.Elements[fi], &file.Elements[ti]
+		id := fmt.Sprintf("edge-%d", i)
+		labelID := id + "-label"
+		x1, y1 := from.X+from.Width/2, from.Y+from.Height/2
+		x2, y2 := to.X+to.Width/2, to.Y+to.Height/2
+		if from == to {
+			// 自引用从矩形右侧绕出再回到顶部。
+			x1, y1 = from.X+from.Width, from.Y+from.Height/2
+			x2, y2 = from.X+from.Width/2, from.Y
+		}
+		label := e.Label
+		if e.Cardinality != "" {
+			label += " (" + e.Cardinality + ")"
+		}
+		arrows = append(arrows,
+			excalidrawElement{
+				ID: id, Type: "arrow", X: x1, Y: y1, Width: math.Abs(x2 - x1), Height: math.Abs(y2 - y1),
+				StrokeColor: "#1e1e1e", BackgroundColor: "transparent", FillStyle: "solid",
+				StrokeWidth: 1, Roughness: 0, Opacity: 100, Seed: nextSeed(),
+				BoundElements: []excalidrawBinding{{ID: labelID, Type: "text"}},
+				Points:        [][2]float64{{0, 0}, {x2 - x1, y2 - y1}},
+				StartBinding:  &excalidrawAnchor{ElementID: from.ID, Gap: 4},
+				EndBinding:    &excalidrawAnchor{ElementID: to.ID, Gap: 4},
+				EndArrowhead:  "arrow",
+			},
+			excalidrawElement{
+				ID: labelID, Type: "text", X: (x1 + x2) / 2, Y: (y1 + y2) / 2,
+				Width: float64(len(label)) * excalidrawCharWidth, Height: excalidrawLineHeight,
+				StrokeColor: "#1e1e1e", BackgroundColor: "transparent", FillStyle: "solid",
+				StrokeWidth: 1, Roughness: 0, Opacity: 100, Seed: nextSeed(),
+				Text: label, OriginalText: label, FontSize: excalidrawFontSize, FontFamily: 3,
+				TextAlign: "center", VerticalAlign: "middle", ContainerID: id,
+			},
+		)
+		from.BoundElements = append(from.BoundElements, excalidrawBinding{ID: id, Type: "arrow"})
+		if to != from {
+			to.BoundElements = append(to.BoundElements, excalidrawBinding{ID: id, Type: "arrow"})
+		}
+	}
+	file.Elements = append(file.Elements, arrows...)
+	return json.MarshalIndent(file, "", "  ")
+}
+
+// excalidrawTextSize 返回容纳多行文本的矩形大小。
+func excalidrawTextSize(lines []string) (width, height float64) {
+	var longest int
+	for _, line := range lines {
+		longest = max(longest, len([]rune(line)))
+	}
+	return float64(longest)*excalidrawCharWidth + 20, float64(len(lines))*excalidrawLineHeight + 20
+}
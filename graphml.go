@@ -0,0 +1,111 @@
+package entviz
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"entgo.io/ent/entc/gen"
+)
+
+type (
+	// graphML 是 GraphML 文档的根元素。
+	graphML struct {
+		XMLName xml.Name     `xml:"graphml"`
+		Xmlns   string       `xml:"xmlns,attr"`
+		Keys    []graphMLKey `xml:"key"`
+		Graph   graphMLGraph `xml:"graph"`
+	}
+
+	// graphMLKey 声明节点或边上的一个数据属性。
+	graphMLKey struct {
+		ID       string `xml:"id,attr"`
+		For      string `xml:"for,attr"`
+		AttrName string `xml:"attr.name,attr"`
+		AttrType string `xml:"attr.type,attr"`
+	}
+
+	// graphMLGraph 包含所有节点和边。
+	graphMLGraph struct {
+		ID          string        `xml:"id,attr"`
+		EdgeDefault string        `xml:"edgedefault,attr"`
+		Nodes       []graphMLNode `xml:"node"`
+		Edges       []graphMLEdge `xml:"edge"`
+	}
+
+	// graphMLNode 是一个实体节点。
+	graphMLNode struct {
+		ID   string        `xml:"id,attr"`
+		Data []graphMLData `xml:"data"`
+	}
+
+	// graphMLEdge 是一条关系。
+	graphMLEdge struct {
+		ID     string        `xml:"id,attr"`
+		Source string        `xml:"source,attr"`
+		Target string        `xml:"target,attr"`
+		Data   []graphMLData `xml:"data"`
+	}
+
+	// graphMLData 是节点或边上某个属性的值。
+	graphMLData struct {
+		Key   string `xml:"key,attr"`
+		Value string `xml:",chardata"`
+	}
+)
+
+// graphMLKeys 是 GraphML 文档中声明的数据属性。
+var graphMLKeys = []graphMLKey{
+	{ID: "name", For: "node", AttrName: "name", AttrType: "string"},
+	{ID: "fields", For: "node", AttrName: "fields", AttrType: "string"},
+	{ID: "label", For: "edge", AttrName: "label", AttrType: "string"},
+	{ID: "cardinality", For: "edge", AttrName: "cardinality", AttrType: "string"},
+}
+
+// GenerateGraphML 生成 GraphML 格式的 schema 图，可以导入 yEd、Gephi 等图编辑工具。
+// 节点带有 name（实体名称）和 fields（每行一个 "name: type" 的字段列表）属性，
+// 边带有 label（关系名称）和 cardinality（基数）属性。
+//
+// 参数：
+//   - g: 包含 schema 信息的 Ent 生成图
+//
+// 返回：
+//   - []byte: GraphML 文档
+//   - error: 如果序列化过程中发生错误则返回错误
+func GenerateGraphML(g *gen.Graph) ([]byte, error) {
+	graph := BuildGraph(g, WithTypeShortening(true))
+	doc := graphML{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys:  graphMLKeys,
+		Graph: graphMLGraph{ID: "schema", EdgeDefault: "directed"},
+	}
+	for _, n := range graph.Nodes {
+		fields := make([]string, len(n.Fields))
+		for i, f := range n.Fields {
+			fields[i] = f.Name + ": " + f.Type
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			ID: n.ID,
+			Data: []graphMLData{
+				{Key: "name", Value: n.ID},
+				{Key: "fields", Value: strings.Join(fields, "\n")},
+			},
+		})
+	}
+	for i, e := range graph.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			ID:     fmt.Sprintf("e%d", i),
+			Source: e.From,
+			Target: e.To,
+			Data: []graphMLData{
+				{Key: "label", Value: e.Label},
+				{Key: "cardinality", Value: e.Cardinality},
+			},
+		})
+	}
+	buf, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(buf, '\n')...), nil
+}
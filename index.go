@@ -0,0 +1,108 @@
+package entviz
+
+import (
+	"strings"
+
+	"entgo.io/ent/entc/gen"
+)
+
+// indexNodes 为实体的每个索引生成一个节点，以及从实体指向该节点的 "index" 边。
+// 节点名称由实体名称和索引列组成，唯一索引附带 unique 标记，例如 "User(name, age) unique"。
+// 索引列对应实体字段时，节点中显示该字段；外键等其他列只显示列名。
+func indexNodes(n *gen.Type, o *options) ([]Node, []Edge) {
+	var (
+		nodes []Node
+		edges []Edge
+	)
+	for _, idx := range n.Indexes {
+		id := n.Name + "(" + strings.Join(idx.Columns, ", ") + ")"
+		if idx.Unique {
+			id += " unique"
+		}
+		node := Node{ID: id, Kind: "index"}
+		for _, column := range idx.Columns {
+			node.Fields = append(node.Fields, indexColumn(n, column, o))
+		}
+		nodes = append(nodes, node)
+		edges = append(edges, Edge{From: n.Name, To: id, Label: "index", Kind: "index"})
+	}
+	return nodes, edges
+}
+
+// indexColumn 返回索引列对应的字段，找不到对应字段时只保留列名。
+func indexColumn(n *gen.Type, column string, o *options) Field {
+	for _, f := range n.Fields {
+		if f.StorageKey() == column {
+			return newField(f, o)
+		}
+	}
+	return Field{Name: column}
+}
+
+// edgeConstraints 找出包含关系外键列的唯一索引，即实体在关系和字段的组合上的唯一约束，
+// 例如同一用户下卡号唯一：index.Fields("number").Edges("owner").Unique()。
+// 约束以 "unique(number, owner)" 的形式记录在实体节点上，也记录在对应的关系边上（多个约束以分号分隔）。
+// 只包含字段的唯一索引已由字段本身或索引节点体现，不在此列出。
+func edgeConstraints(graph Graph, g *gen.Graph) {
+	nodes := make(map[string]int, len(graph.Nodes))
+	for i, n := range graph.Nodes {
+		if n.Kind == "" {
+			nodes[n.ID] = i
+		}
+	}
+	type edgeKey struct{ from, to, label string }
+	edges := make(map[edgeKey]int, len(graph.Edges))
+	for i, e := range graph.Edges {
+		if e.Kind == "" {
+			edges[edgeKey{e.From, e.To, e.Label}] = i
+		}
+	}
+	for _, n := range g.Nodes {
+		for _, idx := range n.Indexes {
+			if !idx.Unique {
+				continue
+			}
+			var (
+				names   []string
+				related []*gen.Edge
+			)
+			for _, column := range idx.Columns {
+				if e := foreignKeyEdge(n, column); e != nil {
+					names, related = append(names, e.Name), append(related, e)
+				} else {
+					names = append(names, indexColumn(n, column, newOptions()).Name)
+				}
+			}
+			if len(related) == 0 {
+				continue
+			}
+			constraint := "unique(" + strings.Join(names, ", ") + ")"
+			if i, ok := nodes[n.Name]; ok {
+				graph.Nodes[i].Constraints = append(graph.Nodes[i].Constraints, constraint)
+			}
+			for _, e := range related {
+				// 反向边不单独绘制，约束记录在它所对应的正向边上。
+				key := edgeKey{n.Name, e.Type.Name, e.Name}
+				if e.IsInverse() && e.Ref != nil {
+					key = edgeKey{e.Type.Name, n.Name, e.Ref.Name}
+				}
+				if i, ok := edges[key]; ok {
+					if graph.Edges[i].Constraint != "" {
+						graph.Edges[i].Constraint += "; "
+					}
+					graph.Edges[i].Constraint += constraint
+				}
+			}
+		}
+	}
+}
+
+// foreignKeyEdge 返回外键列 column 保存在实体 n 的表中的关系，column 不是关系的外键列时返回 nil。
+func foreignKeyEdge(n *gen.Type, column string) *gen.Edge {
+	for _, e := range n.Edges {
+		if (e.Rel.Type == gen.M2O || e.Rel.Type == gen.O2O && e.IsInverse()) && e.Rel.Column() == column {
+			return e
+		}
+	}
+	return nil
+}
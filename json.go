@@ -0,0 +1,330 @@
+package entviz
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+
+	"entgo.io/ent/entc/gen"
+)
+
+// ExportGraphJSON 将 schema 图导出为 JSON，结构与页面中嵌入的图数据一致。
+// 默认使用 Graph 结构体标签中的键名；通过 WithJSONCase 可以将所有键
+// 转换为 snake_case 或 camelCase，以便接入已有的前端或工具。
+//
+// 参数：
+//   - g: 包含 schema 信息的 Ent 生成图
+//   - opts: 可选的生成配置项
+//
+// 返回：
+//   - []byte: JSON 数据
+//   - error: 如果序列化过程中发生错误则返回错误
+func ExportGraphJSON(g *gen.Graph, opts ...Option) ([]byte, error) {
+	o := newOptions(opts...)
+	return marshalGraph(buildGraph(g, o), o)
+}
+
+// GraphFromJSON 从 ExportGraphJSON 导出的 JSON 重建 Graph 模型，与 ExportGraphJSON 互为逆操作，
+// 便于缓存解析后的模型，无需重新运行 entc。使用 WithJSONCase 导出的 snake_case 或 camelCase 键名、
+// 以及代码生成时写入的 schema-viz.json 同样可以读取。
+// 节点必须有唯一的 id，边只能引用存在的节点。
+//
+// 参数：
+//   - data: ExportGraphJSON 导出的 JSON 数据
+//
+// 返回：
+//   - Graph: 重建的图模型
+//   - error: 如果 JSON 无效或节点、边之间的引用无效则返回错误
+func GraphFromJSON(data []byte) (Graph, error) {
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return Graph{}, fmt.Errorf("entviz: invalid graph JSON: %w", err)
+	}
+	// 默认键名本身就是 camelCase，统一转换后即可按结构体标签解析。
+	buf, err := json.Marshal(recase(v, camelCase))
+	if err != nil {
+		return Graph{}, err
+	}
+	return decodeGraph(buf)
+}
+
+// ExportGraphJSONStream 以流的方式将 schema 图写入 w，结构与 ExportGraphJSON 的默认输出一致。
+// 节点逐个转换并编码，不需要先在内存中构建完整的图，适合包含数百个实体的大型 schema。
+// 写入的 JSON 在各个节点和边之间带有换行；没有节点或边时与 ExportGraphJSON 一样写入 null。
+//
+// 参数：
+//   - w: JSON 的写入目标
+//   - g: 包含 schema 信息的 Ent 生成图
+//
+// 返回：
+//   - error: 如果序列化或写入过程中发生错误则返回错误
+func ExportGraphJSONStream(w io.Writer, g *gen.Graph) error {
+	o := newOptions()
+	// 入度需要知道所有指向该实体的边，因此先生成边，边的数据量远小于字段。
+	var edges []Edge
+	for _, n := range g.Nodes {
+		edges = append(edges, newEdges(n, o, nil)...)
+	}
+	in, out := make(map[string]int), make(map[string]int)
+	for _, e := range edges {
+		out[e.From]++
+		in[e.To]++
+	}
+	rels := relations(edges)
+	enc := json.NewEncoder(w)
+	if _, err := io.WriteString(w, `{"nodes":`+jsonListStart(len(g.Nodes))); err != nil {
+		return err
+	}
+	for i, n := range g.Nodes {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		node := newNode(n, o, nil)
+		node.InDegree, node.OutDegree = in[n.Name], out[n.Name]
+		node.Relations = rels[n.Name]
+		if err := enc.Encode(&node); err != nil {
+			return err
+		}
+	}
+	if _, err := io.WriteString(w, jsonListEnd(len(g.Nodes))+`,"edges":`+jsonListStart(len(edges))); err != nil {
+		return err
+	}
+	for i := range edges {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if err := enc.Encode(&edges[i]); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, jsonListEnd(len(edges))+"}\n")
+	return err
+}
+
+// jsonListStart 和 jsonListEnd 返回包裹 n 个元素的 JSON 数组的首尾，
+// 与 encoding/json 对 nil 切片的处理一致，没有元素时整体写作 null。
+func jsonListStart(n int) string {
+	if n == 0 {
+		return "null"
+	}
+	return "["
+}
+
+func jsonListEnd(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return "]"
+}
+
+type (
+	// topology 是只包含实体名称和关系的图，用于 ExportTopologyJSON。
+	topology struct {
+		Nodes []string       `json:"nodes"`
+		Edges []topologyEdge `json:"edges"`
+	}
+
+	topologyEdge struct {
+		From  string `json:"from"`
+		To    string `json:"to"`
+		Label string `json:"label"`
+	}
+)
+
+// ExportTopologyJSON 只导出 schema 图的拓扑结构：实体名称列表和关系，不包含任何字段。
+// 输出远小于 ExportGraphJSON，适合只关心图结构的图算法工具。
+//
+// 输出示例：
+//
+//	{"nodes":["User","Pet"],"edges":[{"from":"User","to":"Pet","label":"pets"}]}
+//
+// 参数：
+//   - g: 包含 schema 信息的 Ent 生成图
+//
+// 返回：
+//   - []byte: JSON 数据
+//   - error: 如果序列化过程中发生错误则返回错误
+func ExportTopologyJSON(g *gen.Graph) ([]byte, error) {
+	graph := BuildGraph(g)
+	t := topology{
+		Nodes: make([]string, 0, len(graph.Nodes)),
+		Edges: make([]topologyEdge, 0, len(graph.Edges)),
+	}
+	for _, n := range graph.Nodes {
+		t.Nodes = append(t.Nodes, n.ID)
+	}
+	for _, e := range graph.Edges {
+		t.Edges = append(t.Edges, topologyEdge{From: e.From, To: e.To, Label: e.Label})
+	}
+	return json.Marshal(t)
+}
+
+// marshalGraph 按配置序列化图模型。
+func marshalGraph(graph Graph, o *options) ([]byte, error) {
+	buf, err := json.Marshal(&graph)
+	if err != nil {
+		return nil, err
+	}
+	if o.omitEmpty {
+		if buf, err = omitEmptyJSON(buf); err != nil {
+			return nil, err
+		}
+	}
+	var convert func(string) string
+	switch o.jsonCase {
+	case "snake":
+		convert = snakeCase
+	case "camel":
+		convert = camelCase
+	default:
+		return buf, nil
+	}
+	// 键名转换不修改结构体标签，而是在通用的 JSON 值上重新命名后再序列化。
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(recase(v, convert))
+}
+
+// omitEmptyJSON 删除 JSON 中所有值为空字符串、false 或 null 的键，例如空的字段注释和未设置的标记，
+// 用于 WithOmitEmpty。数字和数组即使为零值或为空也会保留。
+func omitEmptyJSON(buf []byte) ([]byte, error) {
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(dropEmpty(v))
+}
+
+// dropEmpty 递归地删除 JSON 值中值为空字符串、false 或 null 的键。
+func dropEmpty(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, val := range v {
+			switch val {
+			case "", false, nil:
+				delete(v, k)
+			default:
+				v[k] = dropEmpty(val)
+			}
+		}
+		return v
+	case []any:
+		for i := range v {
+			v[i] = dropEmpty(v[i])
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// recase 递归地转换 JSON 值中所有对象的键名。
+func recase(v any, convert func(string) string) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, val := range v {
+			m[convert(k)] = recase(val, convert)
+		}
+		return m
+	case []any:
+		for i := range v {
+			v[i] = recase(v[i], convert)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// snakeCase 将 camelCase 键名转换为 snake_case，例如 inDegree => in_degree。
+func snakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// camelCase 将 snake_case 键名转换为 camelCase，例如 in_degree => inDegree。
+func camelCase(s string) string {
+	words := strings.Split(s, "_")
+	for i := 1; i < len(words); i++ {
+		if words[i] != "" {
+			words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+		}
+	}
+	return strings.Join(words, "")
+}
+
+type (
+	// manifest 是写入 schema-viz.json 的内容：完整的图数据以及按实体汇总的邻接表。
+	manifest struct {
+		Graph
+		// Adjacency 的键为实体名称，值为与其相邻的实体及连接它们的关系。
+		Adjacency map[string][]neighbor `json:"adjacency"`
+	}
+
+	// neighbor 是邻接表中的一项。Direction 为 out 表示关系由该实体定义，in 表示由相邻实体定义。
+	neighbor struct {
+		Entity    string `json:"entity"`
+		Label     string `json:"label"`
+		Direction string `json:"direction"`
+	}
+)
+
+// generateManifest 生成 schema-viz.json 的内容。下游工具可以直接读取其中的邻接表，
+// 而无需自行解析图数据；邻接表按实体名称、相邻实体、标签和方向排序，保证输出稳定、便于比较差异。
+func generateManifest(g *gen.Graph) ([]byte, error) {
+	graph := BuildGraph(g)
+	return json.MarshalIndent(manifest{Graph: graph, Adjacency: adjacency(graph)}, "", "  ")
+}
+
+// adjacency 返回每个实体的相邻实体。与 relations 相同，混入、索引等特殊的边不参与汇总；
+// 没有任何关系的实体对应空列表。
+func adjacency(graph Graph) map[string][]neighbor {
+	adj := make(map[string][]neighbor, len(graph.Nodes))
+	for _, n := range graph.Nodes {
+		if n.Kind == "" {
+			adj[n.ID] = []neighbor{}
+		}
+	}
+	for _, e := range graph.Edges {
+		if e.Kind != "" && e.Kind != "through" {
+			continue
+		}
+		adj[e.From] = append(adj[e.From], neighbor{Entity: e.To, Label: e.Label, Direction: "out"})
+		if e.To != e.From {
+			adj[e.To] = append(adj[e.To], neighbor{Entity: e.From, Label: e.Label, Direction: "in"})
+		}
+	}
+	for _, neighbors := range adj {
+		slices.SortFunc(neighbors, func(a, b neighbor) int {
+			return cmp.Or(cmp.Compare(a.Entity, b.Entity), cmp.Compare(a.Label, b.Label), cmp.Compare(a.Direction, b.Direction))
+		})
+	}
+	return adj
+}
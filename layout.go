@@ -0,0 +1,100 @@
+package entviz
+
+import (
+	"sort"
+
+	"entgo.io/ent/entc/gen"
+)
+
+const (
+	// layoutNodeSpacing 是同一层相邻节点之间的水平距离。
+	layoutNodeSpacing = 200
+	// layoutSweeps 是按重心调整层内顺序的迭代次数。
+	layoutSweeps = 4
+)
+
+// Layout 使用简化的 Sugiyama 分层算法为每个实体计算确定的坐标，不依赖浏览器中的物理引擎。
+// 实体按依赖关系分层（与 WithTopologicalOrder 的层级相同），层内按相邻实体位置的重心排序以减少交叉。
+// 相同的 schema 总是得到相同的坐标，结果可以直接传给 WithSavedPositions。
+//
+// 参数：
+//   - g: 包含 schema 信息的 Ent 生成图
+//
+// 返回：
+//   - map[string][2]float64: 实体名称到 [x, y] 坐标的映射
+func Layout(g *gen.Graph) map[string][2]float64 {
+	return layoutGraph(BuildGraph(g))
+}
+
+// layoutGraph 为图模型中的每个节点计算分层布局坐标。
+func layoutGraph(graph Graph) map[string][2]float64 {
+	order, levels, _ := topoLevels(graph)
+	var layers [][]string
+	for _, name := range order {
+		l := levels[name]
+		for len(layers) <= l {
+			layers = append(layers, nil)
+		}
+		layers[l] = append(layers[l], name)
+	}
+	neighbors := make(map[string][]string)
+	for _, e := range graph.Edges {
+		if e.From != e.To {
+			neighbors[e.From] = append(neighbors[e.From], e.To)
+			neighbors[e.To] = append(neighbors[e.To], e.From)
+		}
+	}
+	// 交替自上而下和自下而上地按相邻层中邻居位置的平均值重新排序。
+	for sweep := 0; sweep < layoutSweeps; sweep++ {
+		down := sweep%2 == 0
+		for i := range layers {
+			l := i
+			if !down {
+				l = len(layers) - 1 - i
+			}
+			ref := l - 1
+			if !down {
+				ref = l + 1
+			}
+			if ref < 0 || ref >= len(layers) {
+				continue
+			}
+			sortByBarycenter(layers[l], layers[ref], neighbors)
+		}
+	}
+	positions := make(map[string][2]float64, len(order))
+	for l, layer := range layers {
+		offset := float64(len(layer)-1) / 2
+		for i, name := range layer {
+			positions[name] = [2]float64{(float64(i) - offset) * layoutNodeSpacing, float64(l) * defaultLevelSeparation}
+		}
+	}
+	return positions
+}
+
+// sortByBarycenter 按节点在 ref 层中邻居位置的平均值对 layer 稳定排序。
+// 在 ref 层中没有邻居的节点保持当前位置。
+func sortByBarycenter(layer, ref []string, neighbors map[string][]string) {
+	index := make(map[string]int, len(ref))
+	for i, name := range ref {
+		index[name] = i
+	}
+	centers := make(map[string]float64, len(layer))
+	for i, name := range layer {
+		var sum, n float64
+		for _, other := range neighbors[name] {
+			if j, ok := index[other]; ok {
+				sum += float64(j)
+				n++
+			}
+		}
+		if n == 0 {
+			centers[name] = float64(i)
+		} else {
+			centers[name] = sum / n
+		}
+	}
+	sort.SliceStable(layer, func(i, j int) bool {
+		return centers[layer[i]] < centers[layer[j]]
+	})
+}
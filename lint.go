@@ -0,0 +1,45 @@
+package entviz
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// lint 检查 schema 图中的常见质量问题，返回每个问题的描述：
+//   - 没有注释的字段
+//   - 没有任何关系的孤立实体
+//   - 实体之间的循环依赖
+func lint(graph Graph) []string {
+	var warnings []string
+	for _, n := range graph.Nodes {
+		if n.Kind != "" {
+			continue
+		}
+		for _, f := range n.Fields {
+			if f.Comment == "" {
+				warnings = append(warnings, fmt.Sprintf("field %s.%s has no comment", n.ID, f.Name))
+			}
+		}
+		if n.InDegree == 0 && n.OutDegree == 0 {
+			warnings = append(warnings, fmt.Sprintf("entity %s has no relationships", n.ID))
+		}
+	}
+	if _, cyclic := TopologicalSort(graph); len(cyclic) > 0 {
+		warnings = append(warnings, fmt.Sprintf("cyclic dependencies between %s", strings.Join(cyclic, ", ")))
+	}
+	return warnings
+}
+
+// lintError 将 lint 的结果合并为一个错误，没有问题时返回 nil。
+func lintError(graph Graph) error {
+	warnings := lint(graph)
+	if len(warnings) == 0 {
+		return nil
+	}
+	errs := make([]error, len(warnings))
+	for i, w := range warnings {
+		errs[i] = errors.New(w)
+	}
+	return fmt.Errorf("entviz: schema has %d warning(s):\n%w", len(warnings), errors.Join(errs...))
+}
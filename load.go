@@ -0,0 +1,91 @@
+package entviz
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"entgo.io/ent/entc"
+	"entgo.io/ent/entc/gen"
+)
+
+// goFilePos 匹配编译错误中的源文件位置，例如 ent/schema/user.go:12:3。
+var goFilePos = regexp.MustCompile(`([^\s:"]+\.go):\d+`)
+
+// loadPartialGraph 在 schema 加载失败后尽力加载剩余的 schema。
+// 它从错误信息中找出出错的 schema 文件，通过 go build 的 -overlay 参数
+// 将其从包中排除后重新加载，直到加载成功或无法再定位出错的文件。
+//
+// 参数：
+//   - schemaPath: Ent schema 文件所在的目录路径
+//   - cfg: Ent 代码生成配置
+//   - loadErr: 首次加载时返回的错误
+//
+// 返回：
+//   - *gen.Graph: 排除出错文件后加载的图
+//   - []string: 被排除的文件及其错误信息
+//   - error: 如果无法加载任何部分则返回首次加载时的错误
+func loadPartialGraph(schemaPath string, cfg *gen.Config, loadErr error) (*gen.Graph, []string, error) {
+	dir, err := filepath.Abs(schemaPath)
+	if err != nil {
+		return nil, nil, loadErr
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil || len(files) == 0 {
+		return nil, nil, loadErr
+	}
+	tmp, err := os.MkdirTemp("", "entviz-overlay")
+	if err != nil {
+		return nil, nil, loadErr
+	}
+	defer os.RemoveAll(tmp)
+	var (
+		warnings []string
+		overlay  = filepath.Join(tmp, "overlay.json")
+		// 在 overlay 中将文件替换为空字符串表示将其从构建中删除。
+		replace = make(map[string]string)
+	)
+	for err := loadErr; len(replace) < len(files)-1; {
+		file := failedFile(err, dir)
+		if _, ok := replace[file]; ok || file == "" {
+			break
+		}
+		replace[file] = ""
+		msg, _, _ := strings.Cut(err.Error(), "\n")
+		warnings = append(warnings, fmt.Sprintf("%s: %s", filepath.Base(file), msg))
+		buf, merr := json.Marshal(map[string]any{"Replace": replace})
+		if merr != nil {
+			return nil, nil, loadErr
+		}
+		if werr := os.WriteFile(overlay, buf, 0644); werr != nil {
+			return nil, nil, loadErr
+		}
+		c := *cfg
+		c.BuildFlags = append(slices.Clone(cfg.BuildFlags), "-overlay="+overlay)
+		g, lerr := entc.LoadGraph(schemaPath, &c)
+		if lerr == nil {
+			return g, warnings, nil
+		}
+		err = lerr
+	}
+	return nil, nil, loadErr
+}
+
+// failedFile 返回错误信息中第一个位于 dir 目录下的 Go 文件的绝对路径，
+// 找不到时返回空字符串。
+func failedFile(err error, dir string) string {
+	for _, m := range goFilePos.FindAllStringSubmatch(err.Error(), -1) {
+		path, perr := filepath.Abs(m[1])
+		if perr != nil || filepath.Dir(path) != dir {
+			continue
+		}
+		if _, serr := os.Stat(path); serr == nil {
+			return path
+		}
+	}
+	return ""
+}
@@ -0,0 +1,80 @@
+package entviz
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"entgo.io/ent/entc/gen"
+)
+
+// markdownEscaper 转义 Markdown 表格单元格中会破坏表格结构的字符。
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\n", " ")
+
+// GenerateMarkdown 为每个实体生成一个 Markdown 文档，写入 outDir 下的 <实体名称>.md，
+// 便于将 schema 文档接入静态站点生成器。每个文档包含列出名称、类型、注释和标记的字段表格，
+// 以及与该实体相关的关系列表。outDir 不存在时会被创建，已有的同名文件会被覆盖。
+//
+// 参数：
+//   - g: 包含 schema 信息的 Ent 生成图
+//   - outDir: 输出目录
+//
+// 返回：
+//   - error: 如果创建目录或写入文件时发生错误则返回错误
+func GenerateMarkdown(g *gen.Graph, outDir string) error {
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return err
+	}
+	for _, n := range BuildGraph(g, WithTypeShortening(true)).Nodes {
+		if err := os.WriteFile(filepath.Join(outDir, n.ID+".md"), entityMarkdown(n), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// entityMarkdown 返回单个实体的 Markdown 文档。
+func entityMarkdown(n Node) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# %s\n", n.ID)
+	if n.Deprecated {
+		b.WriteString("\n> Deprecated.\n")
+	}
+	b.WriteString("\n## Fields\n\n")
+	if len(n.Fields) == 0 {
+		b.WriteString("No fields.\n")
+	} else {
+		b.WriteString("| Name | Type | Comment | Flags |\n")
+		b.WriteString("| --- | --- | --- | --- |\n")
+		for _, f := range n.Fields {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n",
+				markdownEscaper.Replace(f.Name), markdownEscaper.Replace(f.Type),
+				markdownEscaper.Replace(f.Comment), markdownEscaper.Replace(strings.Join(fieldFlags(f), ", ")))
+		}
+	}
+	b.WriteString("\n## Relationships\n\n")
+	if len(n.Relations) == 0 {
+		b.WriteString("No relationships.\n")
+	}
+	for _, r := range n.Relations {
+		fmt.Fprintf(&b, "- %s\n", r)
+	}
+	return b.Bytes()
+}
+
+// fieldFlags 返回字段的标记，与页面详情面板中显示的标记一致，另外包含外键引用和选择展示的注解。
+func fieldFlags(f Field) []string {
+	var flags []string
+	if f.Optional {
+		flags = append(flags, "optional")
+	}
+	if f.UpdateDefault {
+		flags = append(flags, "on update")
+	}
+	if f.References != "" {
+		flags = append(flags, "→ "+f.References)
+	}
+	return append(flags, f.Tags...)
+}
@@ -0,0 +1,85 @@
+package entviz
+
+import (
+	"bytes"
+	_ "embed"
+	"html/template"
+	"io/fs"
+	"strings"
+
+	"entgo.io/ent/entc/gen"
+)
+
+var (
+	//go:embed matrix.tmpl
+	tmplmatrix string
+	matrixtmpl = template.Must(template.New("matrix").Parse(tmplmatrix))
+)
+
+type (
+	// matrixData 是渲染邻接矩阵页面所需的数据。
+	matrixData struct {
+		FiraCodeCSS template.CSS
+		Entities    []string
+		Rows        []matrixRow
+	}
+
+	// matrixRow 表示矩阵中的一行，即以 Entity 为起点的所有关系。
+	// Cells 与 matrixData.Entities 一一对应，单元格内容为关系名称，
+	// 没有关系时为空字符串。
+	matrixRow struct {
+		Entity string
+		Cells  []string
+	}
+)
+
+// GenerateMatrix 生成以邻接矩阵展示 schema 关系的 HTML 页面。
+// 行和列都是实体，单元格中标出从行实体指向列实体的关系名称，
+// 多个关系以逗号分隔。对于关系密集的 schema，矩阵往往比网络图更清晰。
+//
+// 参数：
+//   - g: 包含 schema 信息的 Ent 生成图
+//
+// 返回：
+//   - []byte: 生成的 HTML 页面字节数组
+//   - error: 如果生成过程中发生错误则返回错误
+func GenerateMatrix(g *gen.Graph) ([]byte, error) {
+	firaCodeCSS, err := fs.ReadFile(assets, "assets/fira_code.css")
+	if err != nil {
+		return nil, err
+	}
+	graph := buildGraph(g, newOptions())
+	index := make(map[string]int, len(graph.Nodes))
+	data := matrixData{FiraCodeCSS: template.CSS(firaCodeCSS)}
+	for i, n := range graph.Nodes {
+		index[n.ID] = i
+		data.Entities = append(data.Entities, n.ID)
+	}
+	labels := make([][][]string, len(graph.Nodes))
+	for i := range labels {
+		labels[i] = make([][]string, len(graph.Nodes))
+	}
+	for _, e := range graph.Edges {
+		from, ok := index[e.From]
+		if !ok {
+			continue
+		}
+		to, ok := index[e.To]
+		if !ok {
+			continue
+		}
+		labels[from][to] = append(labels[from][to], e.Label)
+	}
+	for i, n := range graph.Nodes {
+		row := matrixRow{Entity: n.ID, Cells: make([]string, len(graph.Nodes))}
+		for j := range row.Cells {
+			row.Cells[j] = strings.Join(labels[i][j], ", ")
+		}
+		data.Rows = append(data.Rows, row)
+	}
+	var b bytes.Buffer
+	if err := matrixtmpl.Execute(&b, data); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,74 @@
+package entviz
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"entgo.io/ent/entc/gen"
+)
+
+// MergeGraphs 将多个服务各自的 schema 合并为一张系统全景图。
+// 每个服务的实体名称加上服务名作为命名空间，例如 billing.Invoice。服务名取自生成代码的包路径，
+// 例如 github.com/acme/billing/ent 的服务名为 billing，无法确定或重复时使用 schema1、schema2 等序号。服务内部的关系保持不变；
+// 通过 CrossServiceLink 注解声明的跨服务关系以 "link" 边连接到其他服务的实体，目标不存在时返回错误。
+// 合并结果可以序列化为 JSON 后传给 RenderJSON 生成页面。
+//
+// 参数：
+//   - graphs: 各个服务的 Ent 生成图
+//
+// 返回：
+//   - Graph: 合并后的图模型
+//   - error: 如果跨服务关系引用了不存在的实体则返回列出所有这类关系的错误
+func MergeGraphs(graphs ...*gen.Graph) (Graph, error) {
+	var merged Graph
+	var links []Edge
+	used := make(map[string]bool)
+	for i, g := range graphs {
+		ns := ""
+		if g.Config != nil && g.Config.Package != "" {
+			ns = path.Base(strings.TrimSuffix(g.Config.Package, "/ent"))
+		}
+		if ns == "" || used[ns] {
+			ns = fmt.Sprintf("schema%d", i+1)
+		}
+		used[ns] = true
+		graph := BuildGraph(g)
+		for _, n := range graph.Nodes {
+			n.ID = ns + "." + n.ID
+			n.InDegree, n.OutDegree = 0, 0
+			merged.Nodes = append(merged.Nodes, n)
+		}
+		for _, e := range graph.Edges {
+			e.From, e.To = ns+"."+e.From, ns+"."+e.To
+			merged.Edges = append(merged.Edges, e)
+		}
+		for _, n := range g.Nodes {
+			for _, l := range annotationOf(n).Links {
+				links = append(links, Edge{From: ns + "." + n.Name, To: l.To, Label: l.Label, Kind: "link"})
+			}
+		}
+	}
+	ids := make(map[string]bool, len(merged.Nodes))
+	for _, n := range merged.Nodes {
+		ids[n.ID] = true
+	}
+	var errs []error
+	for _, e := range links {
+		if !ids[e.To] {
+			errs = append(errs, fmt.Errorf("entviz: cross-service link %s.%s references unknown entity %q", e.From, e.Label, e.To))
+			continue
+		}
+		merged.Edges = append(merged.Edges, e)
+	}
+	if len(errs) > 0 {
+		return Graph{}, errors.Join(errs...)
+	}
+	countDegrees(merged)
+	rels := relations(merged.Edges)
+	for i := range merged.Nodes {
+		merged.Nodes[i].Relations = rels[merged.Nodes[i].ID]
+	}
+	return merged, nil
+}
@@ -0,0 +1,71 @@
+package entviz
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"entgo.io/ent/entc/gen"
+)
+
+var (
+	// mermaidArrows 将边的基数映射为 Mermaid ER 图的连线，
+	// 下标 0 用于可选关系，下标 1 用于必填关系。
+	mermaidArrows = map[string][2]string{
+		"1:1": {"||--o|", "||--||"},
+		"1:N": {"||--o{", "||--|{"},
+		"N:1": {"}o--o|", "}o--||"},
+		"N:N": {"}o--o{", "}o--|{"},
+	}
+	// mermaidInvalid 匹配 Mermaid 属性类型中不允许出现的字符。
+	mermaidInvalid = regexp.MustCompile(`[^A-Za-z0-9_\[\]()-]`)
+	// mermaidEscaper 转义 Mermaid 带引号的字符串：Mermaid 不识别反斜杠转义，引号使用实体编码 #quot;，
+	// 字符串不能跨行，换行替换为空格。
+	mermaidEscaper = strings.NewReplacer(`"`, "#quot;", "\r\n", " ", "\n", " ", "\r", " ")
+)
+
+// mermaidString 返回 s 在 Mermaid 中的带引号字符串形式。
+func mermaidString(s string) string {
+	return `"` + mermaidEscaper.Replace(s) + `"`
+}
+
+// GenerateMermaid 生成 Mermaid erDiagram 格式的 schema 描述。
+// 每个实体输出为一个带字段的实体块，每条关系根据其基数和是否必填
+// 选择连线符号，例如可选的一对多关系为 ||--o{，必填的一对多关系为 ||--|{。
+//
+// 参数：
+//   - g: 包含 schema 信息的 Ent 生成图
+//
+// 返回：
+//   - []byte: Mermaid 文本
+//   - error: 如果生成过程中发生错误则返回错误
+func GenerateMermaid(g *gen.Graph) ([]byte, error) {
+	graph := buildGraph(g, newOptions(WithTypeShortening(true)))
+	var b bytes.Buffer
+	b.WriteString("erDiagram\n")
+	for _, n := range graph.Nodes {
+		fmt.Fprintf(&b, "    %s {\n", n.ID)
+		for _, f := range n.Fields {
+			typ := mermaidInvalid.ReplaceAllString(f.Type, "_")
+			if f.Comment != "" {
+				fmt.Fprintf(&b, "        %s %s %s\n", typ, f.Name, mermaidString(f.Comment))
+			} else {
+				fmt.Fprintf(&b, "        %s %s\n", typ, f.Name)
+			}
+		}
+		b.WriteString("    }\n")
+	}
+	for _, e := range graph.Edges {
+		arrows, ok := mermaidArrows[e.Cardinality]
+		if !ok {
+			arrows = mermaidArrows["1:N"]
+		}
+		arrow := arrows[0]
+		if e.Required {
+			arrow = arrows[1]
+		}
+		fmt.Fprintf(&b, "    %s %s %s : %s\n", e.From, arrow, e.To, mermaidString(e.Label))
+	}
+	return b.Bytes(), nil
+}
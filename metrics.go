@@ -0,0 +1,66 @@
+package entviz
+
+import (
+	"slices"
+
+	"entgo.io/ent/entc/gen"
+)
+
+// Metrics 是 schema 的复杂度指标，可以在各个版本之间记录并绘制成趋势图，跟踪 schema 的增长。
+type Metrics struct {
+	// Entities 和 Relationships 是实体和关系的数量。
+	Entities      int `json:"entities"`
+	Relationships int `json:"relationships"`
+	// EdgeNodeRatio 是关系数量与实体数量之比，没有实体时为 0。
+	EdgeNodeRatio float64 `json:"edgeNodeRatio"`
+	// Components 是连通分量的数量，即相互之间没有关系的子系统的数量。
+	Components int `json:"components"`
+	// Cyclomatic 是按 McCabe 公式 E - N + 2P 计算的圈复杂度，E、N、P 分别是关系、实体和连通分量的数量。
+	// 没有环路时等于连通分量的数量，关系图中每多一个独立环路加一。
+	Cyclomatic int `json:"cyclomatic"`
+	// MaxDepth 是最长的依赖链包含的关系数量，依赖方向与 TopologicalSort 相同，循环依赖中的实体不计入。
+	MaxDepth int `json:"maxDepth"`
+	// FanIn 和 FanOut 是入度和出度的分布，键为度数，值为具有该度数的实体数量。
+	FanIn  map[int]int `json:"fanIn"`
+	FanOut map[int]int `json:"fanOut"`
+	// MaxFanIn 和 MaxFanOut 是实体的最大入度和最大出度。
+	MaxFanIn  int `json:"maxFanIn"`
+	MaxFanOut int `json:"maxFanOut"`
+}
+
+// ComplexityMetrics 计算 schema 的复杂度指标：关系与实体的比例、圈复杂度、最长依赖链，以及入度和出度的分布。
+// 指标基于 BuildGraph 使用默认配置得到的图模型计算。
+//
+// 参数：
+//   - g: 包含 schema 信息的 Ent 生成图
+//
+// 返回：
+//   - Metrics: 复杂度指标
+func ComplexityMetrics(g *gen.Graph) Metrics {
+	graph := groupComponents(BuildGraph(g))
+	m := Metrics{
+		Entities:      len(graph.Nodes),
+		Relationships: len(graph.Edges),
+		FanIn:         make(map[int]int),
+		FanOut:        make(map[int]int),
+	}
+	if m.Entities > 0 {
+		m.EdgeNodeRatio = float64(m.Relationships) / float64(m.Entities)
+	}
+	components := make(map[int]bool)
+	for _, n := range graph.Nodes {
+		components[n.Component] = true
+		m.FanIn[n.InDegree]++
+		m.FanOut[n.OutDegree]++
+		m.MaxFanIn, m.MaxFanOut = max(m.MaxFanIn, n.InDegree), max(m.MaxFanOut, n.OutDegree)
+	}
+	m.Components = len(components)
+	m.Cyclomatic = m.Relationships - m.Entities + 2*m.Components
+	_, levels, cyclic := topoLevels(graph)
+	for name, level := range levels {
+		if !slices.Contains(cyclic, name) {
+			m.MaxDepth = max(m.MaxDepth, level)
+		}
+	}
+	return m
+}
@@ -0,0 +1,82 @@
+package entviz
+
+import (
+	"fmt"
+	"strings"
+
+	"entgo.io/ent/entc/gen"
+)
+
+// mixinGroup 表示被多个实体共享的一组混入字段。
+type mixinGroup struct {
+	id       string
+	fields   []*gen.Field
+	entities []string
+}
+
+// sharedMixins 找出被至少两个实体共享的混入字段集合。
+// Ent 只记录字段来自第几个 mixin，而不记录 mixin 的类型，
+// 因此以字段名称和类型组成的签名判断两个实体是否使用了同一个 mixin。
+//
+// 返回：
+//   - []*mixinGroup: 按首次出现顺序排列的共享混入分组
+//   - map[string]map[string]bool: 每个实体中被提取到分组里的字段名称
+func sharedMixins(g *gen.Graph) ([]*mixinGroup, map[string]map[string]bool) {
+	var (
+		groups  []*mixinGroup
+		bySig   = make(map[string]*mixinGroup)
+		members = make(map[*mixinGroup]map[string][]*gen.Field)
+	)
+	for _, n := range g.Nodes {
+		var (
+			order   []int
+			byIndex = make(map[int][]*gen.Field)
+		)
+		for _, f := range n.Fields {
+			if f.Position == nil || !f.Position.MixedIn {
+				continue
+			}
+			if _, ok := byIndex[f.Position.MixinIndex]; !ok {
+				order = append(order, f.Position.MixinIndex)
+			}
+			byIndex[f.Position.MixinIndex] = append(byIndex[f.Position.MixinIndex], f)
+		}
+		for _, i := range order {
+			fields := byIndex[i]
+			sig := make([]string, len(fields))
+			for j, f := range fields {
+				sig[j] = f.Name + ":" + f.Type.String()
+			}
+			key := strings.Join(sig, ",")
+			m, ok := bySig[key]
+			if !ok {
+				m = &mixinGroup{fields: fields}
+				bySig[key] = m
+				members[m] = make(map[string][]*gen.Field)
+				groups = append(groups, m)
+			}
+			m.entities = append(m.entities, n.Name)
+			members[m][n.Name] = fields
+		}
+	}
+	var (
+		shared    []*mixinGroup
+		extracted = make(map[string]map[string]bool)
+	)
+	for _, m := range groups {
+		if len(m.entities) < 2 {
+			continue
+		}
+		m.id = fmt.Sprintf("mixin #%d", len(shared)+1)
+		shared = append(shared, m)
+		for name, fields := range members[m] {
+			if extracted[name] == nil {
+				extracted[name] = make(map[string]bool)
+			}
+			for _, f := range fields {
+				extracted[name][f.Name] = true
+			}
+		}
+	}
+	return shared, extracted
+}
@@ -0,0 +1,54 @@
+package entviz
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// modulePath 从 dir 开始逐级向上查找 go.mod，返回其中声明的模块路径。
+// 找不到或无法解析时返回空字符串。
+func modulePath(dir string) string {
+	if dir == "" {
+		return ""
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		if path, ok := readModulePath(filepath.Join(dir, "go.mod")); ok {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// readModulePath 读取 go.mod 文件中的 module 指令。
+func readModulePath(file string) (string, bool) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		path, ok := strings.CutPrefix(line, "module")
+		if !ok || path == "" || path[0] != ' ' && path[0] != '\t' {
+			continue
+		}
+		path = strings.TrimSpace(path)
+		if unquoted, err := strconv.Unquote(path); err == nil {
+			path = unquoted
+		}
+		return path, path != ""
+	}
+	return "", false
+}
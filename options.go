@@ -0,0 +1,501 @@
+package entviz
+
+type (
+	// Option 用于配置 schema 可视化的生成行为。
+	// 可以传给 NewExtension 或 GeneratePage。
+	Option func(*options)
+
+	// options 保存所有可配置项，零值即为默认行为。
+	options struct {
+		savedPositions     map[string][2]float64
+		shortenTypes       bool
+		inlineCardinality  bool
+		excludeEdges       map[string]bool
+		dropOrphans        bool
+		mixinNodes         bool
+		jsonCase           string
+		throughNodes       bool
+		topological        bool
+		pills              bool
+		bestEffort         bool
+		dialect            string
+		indexNodes         bool
+		customCSS          string
+		collapsed          bool
+		maxFields          int
+		clientHints        bool
+		deterministic      bool
+		nodeMinWidth       int
+		nodeMaxWidth       int
+		levelSeparation    int
+		nodeSpacing        int
+		embedEdges         bool
+		fieldAnnotations   []string
+		treeSelfRefs       bool
+		strict             bool
+		components         bool
+		arrowStyles        map[string]string
+		edgeBundling       bool
+		title              string
+		description        string
+		enumNodes          bool
+		softDeleteField    string
+		connectedOnly      bool
+		rowCounts          map[string]int64
+		labelTranslations  map[string]string
+		transforms         []func(Graph) Graph
+		highlightPattern   string
+		dryRun             bool
+		fieldGrouping      string
+		edgeFontSize       int
+		edgeFontBackground string
+		edgeFontBold       bool
+		muteFields         []string
+		stableIDs          bool
+		summaryTable       bool
+		edgeConstraints    bool
+		direction          string
+		sqlConstraints     bool
+		omitEmpty          bool
+		entityDescriptions bool
+		pageSize           int
+		// warnings 是尽力加载模式下收集的加载警告，会显示在页面顶部。
+		warnings []string
+		// noVendor 表示生成嵌入宿主页面的片段，不内联 vis-network，由 GenerateHTMLNoVendor 设置。
+		noVendor bool
+	}
+)
+
+// 页面布局的默认尺寸，单位为像素。
+const (
+	defaultNodeWidth       = 60
+	defaultLevelSeparation = 250
+	defaultNodeSpacing     = 100
+	defaultEdgeFontSize    = 12
+)
+
+// defaultEdgeFontBackground 是边标签的默认背景色，使标签在与边或其他标签重叠时仍然清晰可读。
+const defaultEdgeFontBackground = "white"
+
+// newOptions 依次应用所有 Option 并返回最终配置。
+func newOptions(opts ...Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithSavedPositions 使用之前导出的节点坐标初始化布局。
+// positions 的键为实体名称，值为 [x, y] 坐标，格式与页面上
+// "export positions" 按钮下载的 JSON 文件一致。
+// 未出现在 positions 中的实体仍然由 vis-network 自动布局。
+func WithSavedPositions(positions map[string][2]float64) Option {
+	return func(o *options) {
+		o.savedPositions = positions
+	}
+}
+
+// WithTypeShortening 控制是否简化字段类型的显示。
+// 开启后，uuid.UUID、time.Time 等常见类型显示为 uuid、time，
+// 通过 GoType 指定的自定义类型去掉包名并附带底层类型，例如 Role(enum)。
+func WithTypeShortening(enabled bool) Option {
+	return func(o *options) {
+		o.shortenTypes = enabled
+	}
+}
+
+// WithInlineCardinality 控制是否将关系基数直接附加到边的标签上，
+// 例如 "posts (1:N)"。适用于只支持单个标签字符串的展示场景。
+func WithInlineCardinality(enabled bool) Option {
+	return func(o *options) {
+		o.inlineCardinality = enabled
+	}
+}
+
+// WithExcludeEdges 隐藏指定名称的关系，例如审计相关的边。
+// 名称既可以是正向边的名称，也可以是其反向边的名称。
+func WithExcludeEdges(names []string) Option {
+	return func(o *options) {
+		o.excludeEdges = make(map[string]bool, len(names))
+		for _, name := range names {
+			o.excludeEdges[name] = true
+		}
+	}
+}
+
+// WithDropOrphans 控制是否移除因 WithExcludeEdges 而失去所有关系的实体。
+// 默认保留这些实体；原本就没有任何关系的实体不受影响。
+func WithDropOrphans(enabled bool) Option {
+	return func(o *options) {
+		o.dropOrphans = enabled
+	}
+}
+
+// WithMixinNodes 控制是否将多个实体共享的混入（mixin）字段提取为单独的节点。
+// 提取后，这些字段只在混入节点中显示一次，使用它们的实体通过
+// 虚线 "includes" 边连接到该节点。
+func WithMixinNodes(enabled bool) Option {
+	return func(o *options) {
+		o.mixinNodes = enabled
+	}
+}
+
+// WithJSONCase 设置 ExportGraphJSON 输出的键名风格，可选 "snake" 或 "camel"。
+// 未设置或取值无法识别时保持默认键名。页面中嵌入的图数据不受影响。
+func WithJSONCase(style string) Option {
+	return func(o *options) {
+		o.jsonCase = style
+	}
+}
+
+// WithThroughNodes 控制是否通过中间实体展示使用 Through 定义的多对多关系。
+// 开启后，这类关系不再直接连接两端实体，而是拆分为经过中间实体的两条边，
+// 以反映关联表在 schema 中的真实结构。
+func WithThroughNodes(enabled bool) Option {
+	return func(o *options) {
+		o.throughNodes = enabled
+	}
+}
+
+// WithTopologicalOrder 控制是否按依赖关系排列实体，使被引用的实体排在前面。
+// 开启后每个实体还会带有分层布局的层级，页面中的分层布局据此自上而下展示依赖层次。
+// 存在循环依赖时，循环中的实体按名称排在最后，可以用 WithStrict 或 TopologicalSort 找出这些实体。
+func WithTopologicalOrder(enabled bool) Option {
+	return func(o *options) {
+		o.topological = enabled
+	}
+}
+
+// WithPills 控制是否在页面中将字段类型显示为按类型分类着色的小标签，
+// 而不是普通文本，便于快速浏览字段较多的实体。
+func WithPills(enabled bool) Option {
+	return func(o *options) {
+		o.pills = enabled
+	}
+}
+
+// WithBestEffort 控制 GeneratePage 在部分 schema 文件无法编译时是否尽力生成页面。
+// 开启后，出错的文件会被排除，页面只展示其余可以加载的实体，
+// 并在顶部以警告横幅列出被排除的文件及错误信息。
+func WithBestEffort(enabled bool) Option {
+	return func(o *options) {
+		o.bestEffort = enabled
+	}
+}
+
+// WithDialect 设置字段数据库列类型所依据的方言，例如 dialect.MySQL、dialect.Postgres。
+// 未设置时使用不区分方言的通用类型映射。
+func WithDialect(name string) Option {
+	return func(o *options) {
+		o.dialect = name
+	}
+}
+
+// WithIndexNodes 控制是否将实体的索引显示为单独的小节点。
+// 每个索引节点通过 "index" 边连接到所属实体，名称中列出索引列，唯一索引带有 unique 标记。
+func WithIndexNodes(enabled bool) Option {
+	return func(o *options) {
+		o.indexNodes = enabled
+	}
+}
+
+// WithCustomCSS 在页面默认样式之后追加一段自定义 CSS，
+// 用于调整字体、间距、颜色等，而无需替换整个模板。
+// 由于追加在最后，其中的规则可以覆盖默认样式。
+func WithCustomCSS(css string) Option {
+	return func(o *options) {
+		o.customCSS = css
+	}
+}
+
+// WithCollapsed 控制页面中的节点是否默认折叠，只显示实体名称。
+// 点击节点可以展开或折叠其字段列表（按住 Ctrl/⌘ 点击则复制实体名称），字段数据始终完整地嵌入在页面中。
+func WithCollapsed(enabled bool) Option {
+	return func(o *options) {
+		o.collapsed = enabled
+	}
+}
+
+// WithMaxFields 限制页面中每个节点最多显示的字段数量，超出部分显示为 "+N more"。
+// 完整的字段列表仍然嵌入在页面中，点击节点后在详情面板中查看。n 不大于 0 时不限制。
+func WithMaxFields(n int) Option {
+	return func(o *options) {
+		o.maxFields = n
+	}
+}
+
+// WithClientHints 控制是否在节点的提示框中显示实体在生成的客户端中的入口，
+// 例如 client.User.Query()，帮助新成员将 schema 与生成的 API 对应起来。
+func WithClientHints(enabled bool) Option {
+	return func(o *options) {
+		o.clientHints = enabled
+	}
+}
+
+// WithDeterministic 控制是否去除页面中所有不确定的内容，使相同的 schema 始终生成
+// 字节完全相同的页面，并且每次打开时的节点颜色和初始布局都相同。
+// 开启后，没有预先生成颜色的节点（例如通过 RenderJSON 渲染的图）也根据名称取色，
+// 布局使用固定的随机种子。
+// 适用于将生成的页面提交到仓库或依赖内容哈希的场景。
+func WithDeterministic(enabled bool) Option {
+	return func(o *options) {
+		o.deterministic = enabled
+	}
+}
+
+// WithNodeSize 设置节点宽度的范围，单位为像素。min 同时作为节点的最小高度。
+// 默认节点宽度固定为 60；放宽 max 可以让较长的实体名称完整显示。
+func WithNodeSize(min, max int) Option {
+	return func(o *options) {
+		o.nodeMinWidth, o.nodeMaxWidth = min, max
+	}
+}
+
+// WithSpacing 设置分层布局中层与层之间以及同层节点之间的距离，单位为像素。
+// 默认分别为 250 和 100，实体较多时增大间距可以减少节点重叠。
+func WithSpacing(levelSeparation, nodeSpacing int) Option {
+	return func(o *options) {
+		o.levelSeparation, o.nodeSpacing = levelSeparation, nodeSpacing
+	}
+}
+
+// WithEmbedEdges 控制是否检测通过 Go 结构体嵌入复用其他 schema 的实体，
+// 并以带空心箭头的 "embeds" 边与普通关系区分展示。
+// Ent 不记录嵌入关系，检测依据是一个实体的字段严格包含另一个实体的全部字段。
+func WithEmbedEdges(enabled bool) Option {
+	return func(o *options) {
+		o.embedEdges = enabled
+	}
+}
+
+// WithFieldAnnotations 选择需要在字段上展示的元数据，以小标签的形式显示在提示框和详情面板中。
+// names 既可以是字段注解的名称（例如 EntSQL、EntGQL），也可以是结构体标签的键（例如 json、validate）。
+func WithFieldAnnotations(names []string) Option {
+	return func(o *options) {
+		o.fieldAnnotations = names
+	}
+}
+
+// WithTreeSelfRefs 控制是否将树形的自引用关系（例如 User 的 parent/children）展示为层级结构。
+// 开启后，这类一对多的自引用不再绘制为自环，而是指向实体下一层的子节点占位，
+// 使组织架构类的 schema 更易阅读。
+func WithTreeSelfRefs(enabled bool) Option {
+	return func(o *options) {
+		o.treeSelfRefs = enabled
+	}
+}
+
+// WithStrict 控制代码生成时是否将 schema 的质量问题视为错误，使 entviz 同时作为 schema 检查工具。
+// 开启后，存在没有注释的字段、没有任何关系的实体或循环依赖时，
+// 钩子返回列出所有问题的错误，代码生成在写入 Ent 代码和页面之前失败。
+// 检查针对完整的 schema，WithDropOrphans、WithConnectedOnly 等过滤选项不会隐藏问题。
+func WithStrict(enabled bool) Option {
+	return func(o *options) {
+		o.strict = enabled
+	}
+}
+
+// WithComponents 控制是否按连通分量分组展示实体。
+// 开启后，彼此之间没有任何关系的子图在页面中相邻排列并拉开距离，
+// 便于发现 schema 中相互独立的子系统。
+func WithComponents(enabled bool) Option {
+	return func(o *options) {
+		o.components = enabled
+	}
+}
+
+// WithArrowStyles 覆盖各基数的边在目标端使用的 vis-network 箭头类型，
+// 键为基数（1:1、1:N、N:1、N:N），值为 arrow、bar、crow、circle、diamond 等箭头类型。
+// 默认目标端为"多"时使用 crow，为"一"时使用 bar，近似 ER 图的乌鸦脚表示法。
+func WithArrowStyles(styles map[string]string) Option {
+	return func(o *options) {
+		o.arrowStyles = styles
+	}
+}
+
+// WithEdgeBundling 控制是否将同一对实体之间标签相同的平行边合并为一条，
+// 标签中附带合并的数量，例如 "posts (×3)"，以减少重复关系造成的杂乱。
+// 实体的入度、出度和关系摘要仍按合并前的每条边统计。默认保留每一条边。
+func WithEdgeBundling(enabled bool) Option {
+	return func(o *options) {
+		o.edgeBundling = enabled
+	}
+}
+
+// WithTitle 设置显示在页面顶部的标题，例如 "Billing Service Schema — v2.3"，
+// 同时用作浏览器标签页的标题。为空时不显示。
+func WithTitle(title string) Option {
+	return func(o *options) {
+		o.title = title
+	}
+}
+
+// WithDescription 设置显示在标题下方的说明文字，保留其中的换行。为空时不显示。
+func WithDescription(description string) Option {
+	return func(o *options) {
+		o.description = description
+	}
+}
+
+// WithEnumNodes 控制是否将枚举字段的类型显示为单独的小节点，节点中列出枚举的全部取值，
+// 并通过 "uses" 边连接到使用它的实体，边的标签为字段名称。
+// 通过 GoType 使用同一个 Go 类型且取值相同的枚举只显示一次，便于发现多个实体共享的枚举；其他枚举按实体分别显示。
+// 默认枚举只作为字段类型显示。
+func WithEnumNodes(enabled bool) Option {
+	return func(o *options) {
+		o.enumNodes = enabled
+	}
+}
+
+// WithSoftDeleteField 设置表示软删除的字段名称，例如 "deleted_at"。
+// 包含该字段的实体（无论字段直接定义还是来自混入）在页面中带有 soft-delete 标记。未设置时不检测。
+func WithSoftDeleteField(name string) Option {
+	return func(o *options) {
+		o.softDeleteField = name
+	}
+}
+
+// WithConnectedOnly 控制是否移除没有任何边的实体，例如独立的查找表，
+// 使图聚焦于相互关联的核心部分。与 WithDropOrphans 不同，它也会移除原本就没有关系的实体。
+func WithConnectedOnly(enabled bool) Option {
+	return func(o *options) {
+		o.connectedOnly = enabled
+	}
+}
+
+// WithRowCounts 为实体附加表行数的估计值，键为实体名称，值通常由调用方从数据库统计得到。
+// 行数以 "~1.2M rows" 的形式显示在节点名称旁边，页面中还可以按行数放大节点，使 ER 图成为简单的容量视图。
+func WithRowCounts(counts map[string]int64) Option {
+	return func(o *options) {
+		o.rowCounts = counts
+	}
+}
+
+// WithLabelTranslations 使用译名替换页面中的实体名称和关系名称，键为原名称，值为译名，
+// 例如 {"User": "用户", "pets": "宠物"}。没有译名的名称保持不变，
+// 从而可以基于同一份 schema 生成不同语言的图。
+func WithLabelTranslations(translations map[string]string) Option {
+	return func(o *options) {
+		o.labelTranslations = translations
+	}
+}
+
+// WithGraphTransform 注册一个在图模型生成之后、序列化之前运行的转换函数，
+// 可以按需增加、过滤或修改节点和边，覆盖没有专门选项的定制需求。
+// 多次使用时按注册顺序依次执行，每个函数接收上一个函数的结果。
+func WithGraphTransform(transform func(Graph) Graph) Option {
+	return func(o *options) {
+		o.transforms = append(o.transforms, transform)
+	}
+}
+
+// WithHighlightPattern 以醒目的边框突出显示名称匹配正则表达式的实体，例如 `.*Event$`，
+// 便于查看按命名约定划分的子系统。正则表达式无效时生成页面返回错误，BuildGraph 则忽略该选项。
+func WithHighlightPattern(pattern string) Option {
+	return func(o *options) {
+		o.highlightPattern = pattern
+	}
+}
+
+// WithDryRun 控制代码生成时是否只生成页面而不写入任何文件，也不生成 ServeEntviz 辅助代码。
+// 生成过程中的错误仍会使代码生成失败，与 WithStrict 一起使用时可以在 CI 中检查 schema 可视化，而不产生文件。
+func WithDryRun(enabled bool) Option {
+	return func(o *options) {
+		o.dryRun = enabled
+	}
+}
+
+// WithFieldGrouping 设置页面中节点字段的分组方式。目前支持 "required-first"：
+// 必填字段排在前面，可选字段排在后面，两组之间以分隔线隔开，与表单中常见的字段布局一致。
+// 未设置或取值无法识别时按定义顺序显示字段。
+func WithFieldGrouping(mode string) Option {
+	return func(o *options) {
+		o.fieldGrouping = mode
+	}
+}
+
+// WithEdgeLabelStyle 设置边标签的字号（像素）、背景色以及是否加粗，用于改善关系密集时标签的可读性。
+// size 为 0 或 background 为空时使用默认值，默认字号为 12，背景为白色。
+func WithEdgeLabelStyle(size int, background string, bold bool) Option {
+	return func(o *options) {
+		o.edgeFontSize, o.edgeFontBackground, o.edgeFontBold = size, background, bold
+	}
+}
+
+// WithMuteFields 以暗淡的样式显示指定名称的字段，例如 created_at、updated_at 等审计字段，
+// 使实体中有业务含义的字段更加突出。
+func WithMuteFields(names []string) Option {
+	return func(o *options) {
+		o.muteFields = names
+	}
+}
+
+// WithStableIDs 使用由 schema 文件名和类型名称组成的稳定键（例如 user.go#User）作为节点 ID，
+// 实体名称作为节点的 Label 显示。保存的布局和指向实体的链接以 ID 为准，不再依赖显示的名称，
+// 例如通过 WithLabelTranslations 翻译名称时不受影响。
+func WithStableIDs(enabled bool) Option {
+	return func(o *options) {
+		o.stableIDs = enabled
+	}
+}
+
+// WithSummaryTable 在页面底部显示统计表，列出每个实体的字段数量以及入边和出边的数量，
+// 点击表头可以按该列排序，便于快速审查 schema。
+func WithSummaryTable(enabled bool) Option {
+	return func(o *options) {
+		o.summaryTable = enabled
+	}
+}
+
+// WithEdgeConstraints 在实体和关系上标注跨越关系的唯一约束，即包含关系外键列的唯一索引，
+// 例如 index.Fields("number").Edges("owner").Unique()。这类业务规则无法从字段的标记中看出。
+func WithEdgeConstraints(enabled bool) Option {
+	return func(o *options) {
+		o.edgeConstraints = enabled
+	}
+}
+
+// WithDirection 设置分层布局的方向："LR"（从左到右）、"RL"（从右到左）、"TB"（从上到下）或 "BT"（从下到上）。
+// 使用 "RL" 时，展开的节点和提示框中的字段列表也改为右对齐，适合阿拉伯语、希伯来语等从右到左书写的文档。
+// 未设置或取值无法识别时使用默认的布局方向。
+func WithDirection(direction string) Option {
+	return func(o *options) {
+		o.direction = direction
+	}
+}
+
+// WithSQLConstraints 在每个字段的类型旁显示类似 DDL 的列定义，例如 varchar(255) NOT NULL DEFAULT 'active'，
+// 包含列类型及长度、非空约束、默认值和唯一约束，列类型取决于 WithDialect 选择的方言。适合 DBA 审查部署后的表结构。
+func WithSQLConstraints(enabled bool) Option {
+	return func(o *options) {
+		o.sqlConstraints = enabled
+	}
+}
+
+// WithOmitEmpty 在导出的图 JSON 和页面嵌入的图数据中省略值为空字符串或 false 的键，
+// 例如空的字段注释和未设置的标记，对元数据稀疏的 schema 可以显著减小 JSON 的体积。
+// 省略的键在读取时按零值处理，GraphFromJSON 仍然可以读取。默认输出完整的键以保持结构稳定。
+func WithOmitEmpty(enabled bool) Option {
+	return func(o *options) {
+		o.omitEmpty = enabled
+	}
+}
+
+// WithEntityDescriptions 在节点的提示框和详情面板中显示实体的说明。说明优先取自 Ent 内置的 schema.Comment 注解，
+// 其次是 schema 源文件中类型定义的文档注释（例如 "User 表示系统中的注册用户。"），
+// 只有从源码加载的 schema 才能读取文档注释，Ent 脚手架生成的默认注释会被忽略。
+func WithEntityDescriptions(enabled bool) Option {
+	return func(o *options) {
+		o.entityDescriptions = enabled
+	}
+}
+
+// WithPageSize 设置每个页面最多包含的实体数量。实体数量超过 n 时，WriteHTML 和代码生成钩子会将页面拆分为多个
+// 通过上一页、下一页链接相连的文件，例如 schema-viz.html、schema-viz-2.html，相互关联的实体尽量放在同一页中。
+// n 为 0 时不分页。上一次生成时多出、这次不再需要的页面会被删除。
+func WithPageSize(n int) Option {
+	return func(o *options) {
+		o.pageSize = n
+	}
+}
@@ -0,0 +1,132 @@
+package entviz
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"entgo.io/ent/entc/gen"
+)
+
+// pageNav 是分页输出中页面之间的导航信息，Pages 为 0 时页面不分页。
+type pageNav struct {
+	// Page 和 Pages 是当前页的序号（从 1 开始）和总页数。
+	Page, Pages int
+	// Prev 和 Next 是上一页和下一页的文件名，不存在时为空。
+	Prev, Next string
+}
+
+// generatePages 生成写入文件的可视化页面，返回文件名到内容的映射。
+// 开启 WithPageSize 且实体数量超过每页的上限时，图被划分为多个页面，第一页使用 name，
+// 其余页面在扩展名之前加上页码，例如 schema-viz-2.html；否则只生成 name 一个页面。
+func generatePages(g *gen.Graph, o *options, name string) (map[string][]byte, error) {
+	if err := checkHighlightPattern(o); err != nil {
+		return nil, err
+	}
+	graph := buildGraph(g, o)
+	if o.pageSize <= 0 || len(graph.Nodes) <= o.pageSize {
+		page, err := renderPage(graph, o, packageData(g))
+		if err != nil {
+			return nil, err
+		}
+		return map[string][]byte{name: page}, nil
+	}
+	parts := paginate(graph, o.pageSize)
+	pages := make(map[string][]byte, len(parts))
+	for i, part := range parts {
+		data := packageData(g)
+		data.Nav = pageNav{Page: i + 1, Pages: len(parts)}
+		if i > 0 {
+			data.Nav.Prev = pageName(name, i)
+		}
+		if i < len(parts)-1 {
+			data.Nav.Next = pageName(name, i+2)
+		}
+		page, err := renderPage(part, o, data)
+		if err != nil {
+			return nil, err
+		}
+		pages[pageName(name, i+1)] = page
+	}
+	return pages, nil
+}
+
+// pageName 返回第 page 页的文件名，第一页就是 name 本身，其余页面在扩展名之前加上页码。
+func pageName(name string, page int) string {
+	if page == 1 {
+		return name
+	}
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "-" + strconv.Itoa(page) + ext
+}
+
+// removeStalePages 删除 dir 中上一次分页生成、这次不再需要的页面，即文件名是 name 的某一页（例如 schema-viz-3.html）
+// 但不在 pages 中的文件。只有页码是数字的文件才会被删除，schema-viz-old.html 这样名称相似的文件保持不变。
+func removeStalePages(dir, name string, pages map[string][]byte) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if _, ok := pages[entry.Name()]; ok || entry.IsDir() || !isPageName(name, entry.Name()) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isPageName 报告 file 是否是 pageName 为 name 生成的第二页或之后的页面。
+func isPageName(name, file string) bool {
+	ext := filepath.Ext(name)
+	page, ok := strings.CutPrefix(file, strings.TrimSuffix(name, ext)+"-")
+	if !ok {
+		return false
+	}
+	page, ok = strings.CutSuffix(page, ext)
+	if !ok {
+		return false
+	}
+	n, err := strconv.Atoi(page)
+	return err == nil && n > 1 && pageName(name, n) == file
+}
+
+// paginate 将图划分为每页最多 size 个节点的子图。节点按连通分量分组，能放进一页的分量不会被拆到两页中，
+// 更大的分量按原有顺序拆分；每页只保留两端都在该页中的边，跨页的关系仍然列在实体的关系摘要中。
+// 划分只取决于 schema 本身，多次生成的结果相同。
+func paginate(graph Graph, size int) []Graph {
+	// groupComponents 会修改节点的分量序号并重新排序，因此在副本上计算，之后恢复原来的序号。
+	components := make(map[string]int, len(graph.Nodes))
+	for _, n := range graph.Nodes {
+		components[n.ID] = n.Component
+	}
+	var pages []Graph
+	for _, component := range splitComponents(groupComponents(Graph{Nodes: slices.Clone(graph.Nodes), Edges: graph.Edges})) {
+		if len(pages) == 0 || len(pages[len(pages)-1].Nodes)+len(component.Nodes) > size && len(component.Nodes) <= size {
+			pages = append(pages, Graph{})
+		}
+		for _, n := range component.Nodes {
+			if len(pages[len(pages)-1].Nodes) == size {
+				pages = append(pages, Graph{})
+			}
+			n.Component = components[n.ID]
+			pages[len(pages)-1].Nodes = append(pages[len(pages)-1].Nodes, n)
+		}
+	}
+	for i, page := range pages {
+		ids := make(map[string]bool, len(page.Nodes))
+		for _, n := range page.Nodes {
+			ids[n.ID] = true
+		}
+		for _, e := range graph.Edges {
+			if ids[e.From] && ids[e.To] {
+				pages[i].Edges = append(pages[i].Edges, e)
+			}
+		}
+	}
+	return pages
+}
@@ -0,0 +1,226 @@
+package entviz
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+
+	"entgo.io/ent/entc/gen"
+)
+
+const (
+	// pdfMargin 是页面内容与纸张边缘之间的距离，单位为点。
+	pdfMargin = 36
+	// pdfFontSize 是实体名称和字段的字号。
+	pdfFontSize = 9
+	// pdfRowHeight 是实体框中每一行的高度。
+	pdfRowHeight = 13
+	// pdfCharWidth 是 Courier 字体单个字符的宽度（字号的 0.6 倍）。
+	pdfCharWidth = pdfFontSize * 0.6
+	// pdfGap 是相邻实体框之间的最小距离。
+	pdfGap = 48
+	// pdfTitleSize 是标题的字号。
+	pdfTitleSize = 16
+)
+
+// pdfLegend 是 PDF 底部图例中的说明。
+var pdfLegend = []string{
+	"Legend",
+	"[Entity]  name, then one row per field: type",
+	"A -> B    relationship defined on A, labeled with its name and cardinality",
+	"1:1 one-to-one   1:N one-to-many   N:1 many-to-one   N:N many-to-many",
+}
+
+// GeneratePDF 生成包含 schema 图、标题和图例的单页 PDF 文档，无需浏览器即可归档或附加到文档中。
+// 实体的位置由 Layout 使用的分层布局计算，每个实体绘制为列出字段的方框，关系绘制为带箭头的连线，
+// 标签包含关系名称和基数。页面大小随图的规模调整。
+//
+// 参数：
+//   - g: 包含 schema 信息的 Ent 生成图
+//
+// 返回：
+//   - []byte: PDF 文件内容
+//   - error: 如果生成过程中发生错误则返回错误
+func GeneratePDF(g *gen.Graph) ([]byte, error) {
+	graph := buildGraph(g, newOptions(WithTypeShortening(true)))
+	positions := layoutGraph(graph)
+
+	// 所有实体框使用相同的网格间距，由最大的实体框决定，避免相互重叠。
+	type box struct {
+		node       Node
+		x, y, w, h float64
+	}
+	boxes := make(map[string]*box, len(graph.Nodes))
+	var cellW, cellH float64
+	for _, n := range graph.Nodes {
+		longest := len(n.ID)
+		for _, f := range n.Fields {
+			longest = max(longest, len(f.Name)+len(f.Type)+2)
+		}
+		b := &box{node: n, w: float64(longest)*pdfCharWidth + 12, h: float64(len(n.Fields)+1)*pdfRowHeight + 4}
+		cellW, cellH = max(cellW, b.w), max(cellH, b.h)
+		boxes[n.ID] = b
+	}
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for id, b := range boxes {
+		pos := positions[id]
+		b.x = pos[0] / layoutNodeSpacing * (cellW + pdfGap)
+		b.y = pos[1] / defaultLevelSeparation * (cellH + pdfGap)
+		minX, minY = min(minX, b.x-b.w/2), min(minY, b.y)
+		maxX, maxY = max(maxX, b.x+b.w/2), max(maxY, b.y+b.h)
+	}
+	if len(boxes) == 0 {
+		minX, minY, maxX, maxY = 0, 0, 0, 0
+	}
+
+	title := "ent schema"
+	if g.Config != nil && g.Config.Package != "" {
+		title += " - " + g.Config.Package
+	}
+	legendH := float64(len(pdfLegend))*pdfRowHeight + 8
+	headerH := float64(pdfTitleSize) + 16
+	var legendW float64
+	for _, line := range pdfLegend {
+		legendW = max(legendW, float64(len(line))*pdfCharWidth+12)
+	}
+	width := max(maxX-minX, legendW, float64(len(title))*pdfTitleSize*0.6) + 2*pdfMargin
+	height := (maxY - minY) + headerH + legendH + pdfGap + 2*pdfMargin
+
+	// PDF 的原点在左下角，toPage 将布局坐标（y 轴向下）转换为页面坐标。
+	toPage := func(x, y float64) (float64, float64) {
+		return x - minX + pdfMargin, height - pdfMargin - headerH - (y - minY)
+	}
+
+	var c pdfContent
+	c.text("F1", pdfTitleSize, pdfMargin, height-pdfMargin-pdfTitleSize, title)
+	for _, e := range graph.Edges {
+		from, to := boxes[e.From], boxes[e.To]
+		if from == nil || to == nil {
+			continue
+		}
+		label := e.Label
+		if e.Cardinality != "" {
+			label += " (" + e.Cardinality + ")"
+		}
+		if from == to {
+			// 自引用绘制为从实体框右侧绕出再回到右侧的环。
+			x, y := toPage(from.x+from.w/2, from.y+from.h/2)
+			c.printf("%.2f %.2f m %.2f %.2f l %.2f %.2f l %.2f %.2f l S\n", x, y+4, x+16, y+4, x+16, y-4, x, y-4)
+			c.text("F2", pdfFontSize-1, x+20, y-3, label)
+			continue
+		}
+		x1, y1 := toPage(from.x, from.y+from.h/2)
+		x2, y2 := toPage(to.x, to.y+to.h/2)
+		// 连线的两端落在实体框的边框上，箭头指向目标实体。
+		sx, sy := pdfClip(x1, y1, x2-x1, y2-y1, from.w/2, from.h/2)
+		ex, ey := pdfClip(x2, y2, x1-x2, y1-y2, to.w/2, to.h/2)
+		c.printf("%.2f %.2f m %.2f %.2f l S\n", sx, sy, ex, ey)
+		c.arrowhead(sx, sy, ex, ey)
+		c.text("F2", pdfFontSize-1, (sx+ex)/2+3, (sy+ey)/2+3, label)
+	}
+	for _, n := range graph.Nodes {
+		b := boxes[n.ID]
+		left, top := toPage(b.x-b.w/2, b.y)
+		c.printf("1 g %.2f %.2f %.2f %.2f re f 0 g\n", left, top-b.h, b.w, b.h)
+		c.printf("0.85 g %.2f %.2f %.2f %.2f re f 0 g\n", left, top-pdfRowHeight-2, b.w, float64(pdfRowHeight+2))
+		c.printf("%.2f %.2f %.2f %.2f re S\n", left, top-b.h, b.w, b.h)
+		c.text("F1", pdfFontSize, left+6, top-pdfRowHeight+2, n.ID)
+		for i, f := range n.Fields {
+			c.text("F2", pdfFontSize, left+6, top-float64(i+2)*pdfRowHeight, f.Name+": "+f.Type)
+		}
+	}
+	legendTop := pdfMargin + legendH
+	c.printf("%.2f %.2f %.2f %.2f re S\n", float64(pdfMargin), float64(pdfMargin), legendW, legendH)
+	for i, line := range pdfLegend {
+		font := "F2"
+		if i == 0 {
+			font = "F1"
+		}
+		c.text(font, pdfFontSize, pdfMargin+6, legendTop-float64(i+1)*pdfRowHeight, line)
+	}
+	return c.document(width, height), nil
+}
+
+// pdfClip 返回从实体框中心 (x, y) 沿方向 (dx, dy) 与半宽 hw、半高 hh 的边框相交的点。
+func pdfClip(x, y, dx, dy, hw, hh float64) (float64, float64) {
+	if dx == 0 && dy == 0 {
+		return x, y
+	}
+	t := math.Inf(1)
+	if dx != 0 {
+		t = min(t, hw/math.Abs(dx))
+	}
+	if dy != 0 {
+		t = min(t, hh/math.Abs(dy))
+	}
+	return x + dx*t, y + dy*t
+}
+
+// pdfContent 收集页面内容流中的绘图指令。
+type pdfContent struct {
+	bytes.Buffer
+}
+
+// printf 追加一条格式化的绘图指令。
+func (c *pdfContent) printf(format string, args ...any) {
+	fmt.Fprintf(c, format, args...)
+}
+
+// text 在 (x, y) 处使用指定字体和字号绘制一行文本。
+func (c *pdfContent) text(font string, size, x, y float64, s string) {
+	c.printf("BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfEscape(s))
+}
+
+// arrowhead 在从 (x1, y1) 到 (x2, y2) 的连线终点绘制实心箭头。
+func (c *pdfContent) arrowhead(x1, y1, x2, y2 float64) {
+	angle := math.Atan2(y2-y1, x2-x1)
+	const size, spread = 7, math.Pi / 7
+	ax, ay := x2-size*math.Cos(angle-spread), y2-size*math.Sin(angle-spread)
+	bx, by := x2-size*math.Cos(angle+spread), y2-size*math.Sin(angle+spread)
+	c.printf("%.2f %.2f m %.2f %.2f l %.2f %.2f l f\n", x2, y2, ax, ay, bx, by)
+}
+
+// document 将内容流包装为完整的单页 PDF 文件，页面使用 PDF 内置的 Helvetica-Bold 和 Courier 字体。
+func (c *pdfContent) document(width, height float64) []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Contents 4 0 R "+
+			"/Resources << /Font << /F1 5 0 R /F2 6 0 R >> >> >>", math.Ceil(width), math.Ceil(height)),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", c.Len(), c.String()),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+	}
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return b.Bytes()
+}
+
+// pdfEscape 转义 PDF 字符串中的特殊字符，内置字体无法显示的非 ASCII 字符替换为 ?。
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,75 @@
+package entviz
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"entgo.io/ent/entc/gen"
+)
+
+// structurizrInvalid 匹配 Structurizr DSL 标识符中不允许出现的字符。
+var structurizrInvalid = regexp.MustCompile(`[^a-z0-9_]`)
+
+// GenerateStructurizr 生成 Structurizr DSL 格式的 C4 模型，便于将 schema 纳入基于 C4 的架构文档。
+// 生成的代码包作为一个软件系统，每个实体是其中的一个容器，描述中列出字段；
+// 每条关系输出为容器之间的一条关系线，并附带关系名称和基数。
+//
+// 参数：
+//   - g: 包含 schema 信息的 Ent 生成图
+//
+// 返回：
+//   - []byte: Structurizr DSL 文本
+//   - error: 如果生成过程中发生错误则返回错误
+func GenerateStructurizr(g *gen.Graph) ([]byte, error) {
+	graph := buildGraph(g, newOptions(WithTypeShortening(true)))
+	system := "ent"
+	if g.Config != nil && g.Config.Package != "" {
+		system = path.Base(g.Config.Package)
+	}
+	// 不同的实体名称可能得到相同的标识符（例如 user_group 和 UserGroup），重复时加上数字后缀。
+	ids := make(map[string]string, len(graph.Nodes))
+	used := map[string]bool{"entviz_system": true}
+	for _, n := range graph.Nodes {
+		id := structurizrID(n.ID)
+		for i := 2; used[id]; i++ {
+			id = structurizrID(n.ID) + "_" + strconv.Itoa(i)
+		}
+		ids[n.ID], used[id] = id, true
+	}
+
+	var b bytes.Buffer
+	b.WriteString("workspace {\n    model {\n")
+	fmt.Fprintf(&b, "        entviz_system = softwareSystem %s {\n", structurizrString(system))
+	for _, n := range graph.Nodes {
+		fields := make([]string, len(n.Fields))
+		for i, f := range n.Fields {
+			fields[i] = f.Name + ": " + f.Type
+		}
+		fmt.Fprintf(&b, "            %s = container %s %s \"Ent entity\"\n",
+			ids[n.ID], structurizrString(n.ID), structurizrString(strings.Join(fields, ", ")))
+	}
+	b.WriteString("        }\n")
+	for _, e := range graph.Edges {
+		label := e.Label
+		if e.Cardinality != "" {
+			label += " (" + e.Cardinality + ")"
+		}
+		fmt.Fprintf(&b, "        %s -> %s %s\n", ids[e.From], ids[e.To], structurizrString(label))
+	}
+	b.WriteString("    }\n    views {\n        container entviz_system {\n            include *\n            autolayout lr\n        }\n    }\n}\n")
+	return b.Bytes(), nil
+}
+
+// structurizrID 将实体名称转换为 Structurizr DSL 的标识符，例如 UserGroup => user_group。
+func structurizrID(name string) string {
+	return structurizrInvalid.ReplaceAllString(snakeCase(name), "_")
+}
+
+// structurizrString 返回带双引号的 DSL 字符串，其中的双引号替换为单引号。
+func structurizrString(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "'") + `"`
+}
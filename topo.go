@@ -0,0 +1,128 @@
+package entviz
+
+import (
+	"slices"
+	"sort"
+)
+
+// dependencies 返回每个实体所依赖（引用）的实体集合。
+// 依赖方向由外键所在的一侧决定：一对一和一对多关系的外键位于目标实体，
+// 因此目标实体依赖起点实体；多对一关系则相反。多对多关系通过关联表连接，
+// 自引用关系和特殊边（如 includes）不产生依赖。
+func dependencies(graph Graph) map[string]map[string]bool {
+	deps := make(map[string]map[string]bool, len(graph.Nodes))
+	for _, n := range graph.Nodes {
+		deps[n.ID] = make(map[string]bool)
+	}
+	for _, e := range graph.Edges {
+		if e.From == e.To || e.Kind != "" {
+			continue
+		}
+		from, to := e.From, e.To
+		switch e.Cardinality {
+		case "1:1", "1:N":
+			from, to = to, from
+		case "N:1":
+		default:
+			continue
+		}
+		if _, ok := deps[from]; !ok {
+			continue
+		}
+		if _, ok := deps[to]; !ok {
+			continue
+		}
+		deps[from][to] = true
+	}
+	return deps
+}
+
+// TopologicalSort 按依赖关系对实体排序，被引用的实体排在引用它的实体之前。
+// 同一层级内按名称排序以保证结果稳定。
+//
+// 参数：
+//   - graph: schema 图模型
+//
+// 返回：
+//   - []string: 排序后的实体名称
+//   - []string: 处于循环依赖中、无法排序的实体名称，按名称排序后位于 order 的末尾
+func TopologicalSort(graph Graph) (order []string, cyclic []string) {
+	order, _, cyclic = topoLevels(graph)
+	return order, cyclic
+}
+
+// topoLevels 使用 Kahn 算法计算拓扑顺序以及每个实体在依赖层次中的层级，
+// 层级可作为 vis-network 分层布局的位置提示。循环依赖中的实体放在最后一层。
+func topoLevels(graph Graph) ([]string, map[string]int, []string) {
+	deps := dependencies(graph)
+	var (
+		order   []string
+		levels  = make(map[string]int, len(deps))
+		pending = make(map[string]int, len(deps))
+		users   = make(map[string][]string, len(deps))
+		ready   []string
+	)
+	for name, d := range deps {
+		pending[name] = len(d)
+		for dep := range d {
+			users[dep] = append(users[dep], name)
+		}
+		if len(d) == 0 {
+			ready = append(ready, name)
+		}
+	}
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		var next []string
+		for _, name := range ready {
+			order = append(order, name)
+			for _, user := range users[name] {
+				if levels[name]+1 > levels[user] {
+					levels[user] = levels[name] + 1
+				}
+				if pending[user]--; pending[user] == 0 {
+					next = append(next, user)
+				}
+			}
+		}
+		ready = next
+	}
+	var cyclic []string
+	for name, n := range pending {
+		if n > 0 {
+			cyclic = append(cyclic, name)
+		}
+	}
+	sort.Strings(cyclic)
+	last := 0
+	for _, name := range order {
+		if levels[name] >= last {
+			last = levels[name] + 1
+		}
+	}
+	for _, name := range cyclic {
+		levels[name] = last
+	}
+	return append(order, cyclic...), levels, cyclic
+}
+
+// sortTopologically 按拓扑顺序重新排列图中的节点，并为每个节点设置分层布局的层级。
+// 存在循环依赖时，循环中的实体按名称排在最后，并作为第二个返回值返回。
+func sortTopologically(graph Graph) (Graph, []string) {
+	order, levels, cyclic := topoLevels(graph)
+	rank := make(map[string]int, len(order))
+	for i, name := range order {
+		rank[name] = i
+	}
+	// 按节点在切片中的位置排序而不是按 ID 放置，ID 重复的节点（例如翻译成相同名称的实体）也都会保留。
+	nodes := slices.Clone(graph.Nodes)
+	for i := range nodes {
+		level := levels[nodes[i].ID]
+		nodes[i].Level = &level
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return rank[nodes[i].ID] < rank[nodes[j].ID]
+	})
+	graph.Nodes = nodes
+	return graph, cyclic
+}
@@ -0,0 +1,69 @@
+package entviz
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"entgo.io/ent/entc/gen"
+)
+
+// embeddedGraph 匹配页面中嵌入的图数据，html/template 会在 JS 值两侧补充空格。
+var embeddedGraph = regexp.MustCompile(`(?m)^\s*const entGraph = (.*);$`)
+
+// ValidateHTML 检查生成的页面是否完整，可用于 CI 中对生成结果做简单的冒烟测试。
+// 它从页面中取出嵌入的图数据，确认其可以被解析、节点和边之间的引用有效，
+// 并且包含以相同配置生成页面时应有的每一个实体。opts 需要与生成页面时使用的配置一致，
+// 例如 WithStableIDs 和 WithLabelTranslations 会改变节点 ID，WithDropOrphans 会移除实体。
+// 使用 WithPageSize 分页生成的页面需要用 ValidatePages 一起检查。
+//
+// 参数：
+//   - html: 生成的 HTML 页面
+//   - g: 生成页面时使用的 Ent 生成图
+//   - opts: 生成页面时使用的配置选项
+//
+// 返回：
+//   - error: 页面中缺少图数据、图数据无效或缺少实体时返回错误
+func ValidateHTML(html []byte, g *gen.Graph, opts ...Option) error {
+	return ValidatePages([][]byte{html}, g, opts...)
+}
+
+// ValidatePages 检查分页生成的一组页面是否完整：每个页面的图数据都必须有效，
+// 所有页面合起来包含以相同配置生成时应有的每一个实体。只有一个页面时与 ValidateHTML 相同。
+//
+// 参数：
+//   - pages: 生成的全部 HTML 页面，例如 schema-viz.html、schema-viz-2.html 等文件的内容
+//   - g: 生成页面时使用的 Ent 生成图
+//   - opts: 生成页面时使用的配置选项
+//
+// 返回：
+//   - error: 页面中缺少图数据、图数据无效或所有页面合起来缺少实体时返回错误
+func ValidatePages(pages [][]byte, g *gen.Graph, opts ...Option) error {
+	if len(pages) == 0 {
+		return errors.New("entviz: no pages to validate")
+	}
+	ids := make(map[string]bool)
+	for i, html := range pages {
+		m := embeddedGraph.FindSubmatch(html)
+		if m == nil {
+			return fmt.Errorf("entviz: no graph data found in page %d", i+1)
+		}
+		graph, err := decodeGraph(m[1])
+		if err != nil {
+			return err
+		}
+		for _, n := range graph.Nodes {
+			ids[n.ID] = true
+		}
+	}
+	var missing []string
+	for _, n := range buildGraph(g, newOptions(opts...)).Nodes {
+		if !ids[n.ID] {
+			missing = append(missing, n.ID)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("entviz: page is missing entities %q", missing)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package entviz
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"entgo.io/ent/entc/gen"
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce 是最后一次变化后等待的时间，连续保存只触发一次重新生成。
+const watchDebounce = 300 * time.Millisecond
+
+// Watch 使用 fsnotify 监视 schema 目录，在其中的 Go 文件发生变化时重新生成可视化页面并写入 outPath，
+// 直到 ctx 被取消。适用于在浏览器中直接打开 HTML 文件、修改 schema 后刷新页面的预览方式。
+//
+// 启动时会先生成一次页面。短时间内的连续修改会合并为一次重新生成；
+// 生成失败（例如 schema 正在编辑、暂时无法编译）只记录日志，不会停止监视。
+//
+// 参数：
+//   - ctx: 控制监视的生命周期，取消后 Watch 返回
+//   - schemaPath: Ent schema 文件所在的目录路径
+//   - outPath: HTML 页面的输出路径
+//   - cfg: Ent 代码生成配置，如果为 nil 则使用默认配置
+//   - opts: 可选的生成配置项
+//
+// 返回：
+//   - error: 无法监视 schema 目录时返回错误，ctx 被取消时返回 nil
+func Watch(ctx context.Context, schemaPath, outPath string, cfg *gen.Config, opts ...Option) error {
+	return watch(ctx, schemaPath, watchDebounce, func() error {
+		buf, err := GeneratePage(schemaPath, cfg, opts...)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(outPath, buf, 0644)
+	})
+}
+
+// watch 监视 dir 中 Go 文件的变化，文件在 debounce 时间内不再变化后调用 rebuild。
+// 启动时会先调用一次 rebuild。
+func watch(ctx context.Context, dir string, debounce time.Duration, rebuild func() error) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	if err := watcher.Add(dir); err != nil {
+		return err
+	}
+	run := func() {
+		if err := rebuild(); err != nil {
+			log.Printf("entviz: regenerating %s: %v", dir, err)
+		}
+	}
+	run()
+	// timer 在最后一次变化 debounce 之后触发，每次变化都会重新计时。
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			// 只修改权限不改变 schema；编辑器的临时文件等非 Go 文件也忽略。
+			if filepath.Ext(event.Name) != ".go" || event.Op == fsnotify.Chmod {
+				continue
+			}
+			timer.Reset(debounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return err
+		case <-timer.C:
+			run()
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package entviz
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"entgo.io/ent/entc/gen"
+)
+
+var (
+	// yamlPlain 匹配可以不加引号直接写入 YAML 的字符串。
+	yamlPlain = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+	// yamlReserved 是 YAML 解析时会被当作布尔值或空值的单词。
+	yamlReserved = map[string]bool{
+		"true": true, "false": true, "yes": true, "no": true,
+		"on": true, "off": true, "null": true, "y": true, "n": true,
+	}
+)
+
+// GenerateYAML 生成 YAML 格式的 schema 结构描述，
+// 包含所有实体及其字段（名称、类型、是否可选、注释），以及实体之间的关系。
+// 相比 JSON，YAML 更适合放入文档流水线，也更便于比较差异。
+//
+// 参数：
+//   - g: 包含 schema 信息的 Ent 生成图
+//
+// 返回：
+//   - []byte: YAML 文本
+//   - error: 如果生成过程中发生错误则返回错误
+func GenerateYAML(g *gen.Graph) ([]byte, error) {
+	graph := BuildGraph(g)
+	var b bytes.Buffer
+	if len(graph.Nodes) == 0 {
+		b.WriteString("entities: []\n")
+	} else {
+		b.WriteString("entities:\n")
+	}
+	for _, n := range graph.Nodes {
+		fmt.Fprintf(&b, "  - name: %s\n", yamlString(n.ID))
+		if len(n.Fields) == 0 {
+			b.WriteString("    fields: []\n")
+			continue
+		}
+		b.WriteString("    fields:\n")
+		for _, f := range n.Fields {
+			fmt.Fprintf(&b, "      - name: %s\n", yamlString(f.Name))
+			fmt.Fprintf(&b, "        type: %s\n", yamlString(f.Type))
+			fmt.Fprintf(&b, "        optional: %t\n", f.Optional)
+			if f.Comment != "" {
+				fmt.Fprintf(&b, "        comment: %s\n", yamlString(f.Comment))
+			}
+		}
+	}
+	if len(graph.Edges) == 0 {
+		b.WriteString("edges: []\n")
+	} else {
+		b.WriteString("edges:\n")
+	}
+	for _, e := range graph.Edges {
+		fmt.Fprintf(&b, "  - from: %s\n", yamlString(e.From))
+		fmt.Fprintf(&b, "    to: %s\n", yamlString(e.To))
+		fmt.Fprintf(&b, "    label: %s\n", yamlString(e.Label))
+		fmt.Fprintf(&b, "    cardinality: %s\n", yamlString(e.Cardinality))
+		fmt.Fprintf(&b, "    required: %t\n", e.Required)
+	}
+	return b.Bytes(), nil
+}
+
+// yamlString 返回字符串在 YAML 中的表示，必要时使用双引号转义。
+// Go 的双引号转义序列同时也是合法的 YAML 转义序列。
+func yamlString(s string) string {
+	if yamlPlain.MatchString(s) && !yamlReserved[strings.ToLower(s)] {
+		return s
+	}
+	return strconv.Quote(s)
+}